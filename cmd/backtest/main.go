@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"splash-trading-bot/lib/models"
+	"splash-trading-bot/src/backtest"
+	"splash-trading-bot/src/client"
+	"splash-trading-bot/src/recorder"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch os.Args[1] {
+	case "record":
+		record(ctx, os.Args[2:])
+	case "replay":
+		replay(ctx, os.Args[2:])
+	case "sweep":
+		sweep(ctx, os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: backtest record -out data/ticks [-duration 1h] [-interval 100ms] [-rotate 1h]")
+	fmt.Fprintln(os.Stderr, "       backtest replay -data data/ticks [-config config.json] [-json]")
+	fmt.Fprintln(os.Stderr, "       backtest sweep -data data/ticks -grid grid.json [-parallel N] [-rank pnl] [-top 20] [-out results.json]")
+	os.Exit(2)
+}
+
+func record(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("record", flag.ExitOnError)
+	out := fs.String("out", "data/ticks", "output directory")
+	duration := fs.Duration("duration", time.Hour, "how long to record")
+	interval := fs.Duration("interval", 100*time.Millisecond, "polling interval")
+	rotate := fs.Duration("rotate", recorder.DefaultRotateEvery, "start a new file after this period")
+	fs.Parse(args)
+
+	writer, err := recorder.NewWriter(recorder.Options{Dir: *out, RotateEvery: *rotate})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer writer.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, *duration)
+	defer cancel()
+
+	source := client.NewLiveSource(*interval)
+	defer source.Stop()
+
+	frames := 0
+	for {
+		tickers, at, err := source.Next(ctx)
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			// ошибки и паузы ленты уже залогированы LiveSource
+			continue
+		}
+		if err := writer.Record(tickers, at); err != nil {
+			log.Fatalf("Recorder write error: %v", err)
+		}
+		frames++
+		if frames%600 == 0 {
+			log.Printf("Recorded %d frames", frames)
+		}
+	}
+	log.Printf("Recording finished: %d frames written to %s", frames, *out)
+}
+
+func replay(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	data := fs.String("data", "data/ticks", "recorded tick file or directory")
+	configPath := fs.String("config", "", "engine config JSON (defaults to built-in tiers)")
+	asJSON := fs.Bool("json", false, "print report as JSON")
+	fs.Parse(args)
+
+	cfg := models.DefaultConfig()
+	if *configPath != "" {
+		raw, err := os.ReadFile(*configPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			log.Fatalf("Invalid config %s: %v", *configPath, err)
+		}
+	}
+
+	log.SetOutput(os.Stderr)
+	report, err := backtest.Run(ctx, *data, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *asJSON {
+		json.NewEncoder(os.Stdout).Encode(report)
+		return
+	}
+
+	fmt.Printf("Config:        %s\n", report.ConfigHash[:12])
+	fmt.Printf("Frames:        %d (%s .. %s)\n", report.Frames, report.From.Format(time.DateTime), report.To.Format(time.DateTime))
+	fmt.Printf("Signals:       %d (returned %d, timeout %d, open %d)\n", report.Signals, report.Returned, report.Timeouts, report.Open)
+	if report.DataGaps > 0 {
+		fmt.Printf("Data gaps:     %d signals near a feed gap\n", report.DataGaps)
+	}
+	fmt.Printf("Return rate:   %.1f%%\n", report.ReturnRate*100)
+	fmt.Printf("Median return: %s\n", report.MedianReturnTime.Round(time.Millisecond))
+	fmt.Printf("Paper trades:  %d (wins %d), PnL %.2f USDT\n", report.PaperTrades, report.PaperWins, report.PaperPnL)
+}
+
+func sweep(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("sweep", flag.ExitOnError)
+	data := fs.String("data", "data/ticks", "recorded tick file or directory")
+	gridPath := fs.String("grid", "grid.json", "grid definition JSON")
+	parallel := fs.Int("parallel", runtime.NumCPU(), "number of concurrent replays")
+	rankBy := fs.String("rank", "pnl", "rank key: "+strings.Join(backtest.RankKeys, ", "))
+	top := fs.Int("top", 20, "how many results to print (0 = all)")
+	out := fs.String("out", "", "write all results as JSON to this file")
+	fs.Parse(args)
+
+	raw, err := os.ReadFile(*gridPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// как и replay без -config, база и каждая конфигурация из configs дополняют models.DefaultConfig
+	grid := backtest.Grid{Base: models.DefaultConfig()}
+	if err := json.Unmarshal(raw, &grid); err != nil {
+		log.Fatalf("Invalid grid %s: %v", *gridPath, err)
+	}
+
+	candidates := grid.Expand()
+	if len(candidates) == 0 {
+		log.Fatal("Grid produced no configurations")
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		log.Fatal(err)
+	}
+	tmpDir, err := os.MkdirTemp("", "splash-sweep-")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if *parallel < 1 {
+		*parallel = 1
+	}
+	log.Printf("Sweep: %d configurations, %d in parallel", len(candidates), *parallel)
+
+	// Движок хранит состояние в глобальных переменных, поэтому каждый прогон идет в отдельном процессе.
+	results := make([]backtest.SweepResult, len(candidates))
+	sem := make(chan struct{}, *parallel)
+	var wg sync.WaitGroup
+	var done atomic.Int32
+
+	for i, c := range candidates {
+		wg.Add(1)
+		go func(i int, c backtest.Candidate) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = runCandidate(ctx, self, tmpDir, *data, i, c)
+			log.Printf("[%d/%d] %s", done.Add(1), len(candidates), c.Label)
+		}(i, c)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		log.Fatal("Sweep interrupted")
+	}
+	if err := backtest.Rank(results, *rankBy); err != nil {
+		log.Fatal(err)
+	}
+
+	if *out != "" {
+		encoded, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(*out, encoded, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tTIERS\tSIGNALS\tRETURN RATE\tMEDIAN RT\tPAPER PNL\tTRADES")
+	for i, r := range results {
+		if *top > 0 && i >= *top {
+			break
+		}
+		if r.Error != "" {
+			fmt.Fprintf(w, "%d\t%s\terror: %s\t\t\t\t\n", i+1, r.Label, r.Error)
+			continue
+		}
+		rep := r.Report
+		fmt.Fprintf(w, "%d\t%s\t%d\t%.1f%%\t%s\t%.2f\t%d\n", i+1, r.Label, rep.Signals, rep.ReturnRate*100,
+			rep.MedianReturnTime.Round(time.Millisecond), rep.PaperPnL, rep.PaperTrades)
+	}
+	w.Flush()
+}
+
+func runCandidate(ctx context.Context, self, tmpDir, data string, idx int, c backtest.Candidate) backtest.SweepResult {
+	result := backtest.SweepResult{Candidate: c}
+
+	encoded, err := json.Marshal(c.Config)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	cfgPath := filepath.Join(tmpDir, fmt.Sprintf("config-%d.json", idx))
+	if err := os.WriteFile(cfgPath, encoded, 0o644); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	cmd := exec.CommandContext(ctx, self, "replay", "-data", data, "-config", cfgPath, "-json")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		result.Error = strings.TrimSpace(lastLine(stderr.String()))
+		if result.Error == "" {
+			result.Error = err.Error()
+		}
+		return result
+	}
+
+	if err := json.Unmarshal(output, &result.Report); err != nil {
+		result.Error = fmt.Sprintf("invalid replay output: %v", err)
+	}
+	return result
+}
+
+func lastLine(s string) string {
+	s = strings.TrimRight(s, "\n")
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
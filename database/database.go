@@ -21,7 +21,7 @@ func InitDatabase(dataSourceName string) error {
 		dbname   = ""
 	)
 
-	connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		host, port, user, password, dbname)
 
 	var err error
@@ -58,23 +58,129 @@ func InitDatabase(dataSourceName string) error {
         return_time float8 default 0,
         max_deviation float8 default 0,
         prob_win float8 default 0,
-		time_window smallint not null
+		time_window smallint not null,
+		tolerance_mode varchar(10) not null default 'auto',
+		tolerance float8 default 0,
+		level_scale float8 not null default 1,
+		status varchar(12) not null default 'ACTIVE',
+		forced_pin boolean not null default false,
+		data_gap boolean not null default false
 	);`
 
 	_, err = DB.Exec(createTablePSQL)
 	if err != nil {
 		return fmt.Errorf("failed to create splash_records table: %w", err)
 	}
+
+	migratePSQL := `
+	alter table splash_records
+		add column if not exists tolerance_mode varchar(10) not null default 'auto',
+		add column if not exists tolerance float8 default 0,
+		add column if not exists level_scale float8 not null default 1,
+		add column if not exists status varchar(12),
+		add column if not exists forced_pin boolean not null default false,
+		add column if not exists data_gap boolean not null default false;`
+
+	_, err = DB.Exec(migratePSQL)
+	if err != nil {
+		return fmt.Errorf("failed to migrate splash_records table: %w", err)
+	}
+
+	// Раньше returned = true ставилось и при таймауте; исход старых записей восстанавливаем по времени возврата.
+	backfillPSQL := `
+	update splash_records
+	set status = case
+			when returned = false then 'ACTIVE'
+			when return_time < time_window * 60 then 'RETURNED'
+			else 'TIMEOUT'
+		end,
+		returned = (returned = true and return_time < time_window * 60)
+	where status is null;
+	alter table splash_records
+		alter column status set default 'ACTIVE',
+		alter column status set not null;
+	drop index if exists idx_active_splash;`
+
+	_, err = DB.Exec(backfillPSQL)
+	if err != nil {
+		return fmt.Errorf("failed to migrate splash_records status: %w", err)
+	}
+
 	createIndexPSQL := `
-		CREATE UNIQUE INDEX IF NOT EXISTS idx_active_splash 
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_active_splash_status 
 		ON splash_records (symbol, trigger_level) 
-		WHERE (returned = false);`
+		WHERE (status = 'ACTIVE');`
 
 	_, err = DB.Exec(createIndexPSQL)
 	if err != nil {
 		log.Printf("Warning: Could not create unique index: %v", err)
 	}
 
+	createPaperPSQL := `
+	create table if not exists paper_trades(
+		id serial primary key,
+		record_id integer references splash_records(id) on delete set null,
+		symbol varchar(30) not null,
+		side varchar(10) not null,
+		trigger_level smallint not null,
+		size float8 not null,
+		entry_price float8 not null,
+		exit_price float8 not null,
+		stop_price float8 default 0,
+		entry_time timestamp with time zone not null,
+		exit_time timestamp with time zone not null,
+		exit_reason varchar(20) not null,
+		fees float8 default 0,
+		pnl float8 not null,
+		pnl_percent float8 not null
+	);`
+
+	_, err = DB.Exec(createPaperPSQL)
+	if err != nil {
+		return fmt.Errorf("failed to create paper_trades table: %w", err)
+	}
+
+	createProfilesPSQL := `
+	create table if not exists config_profiles(
+		id serial primary key,
+		name varchar(64) not null unique,
+		config jsonb not null,
+		is_active boolean not null default false,
+		created_at timestamp with time zone not null default now(),
+		updated_at timestamp with time zone not null default now()
+	);
+	create table if not exists config_profile_history(
+		id serial primary key,
+		profile_id integer not null references config_profiles(id) on delete cascade,
+		config jsonb not null,
+		saved_at timestamp with time zone not null default now()
+	);`
+
+	_, err = DB.Exec(createProfilesPSQL)
+	if err != nil {
+		return fmt.Errorf("failed to create config profile tables: %w", err)
+	}
+
+	createVersionsPSQL := `
+	create table if not exists config_versions(
+		id serial primary key,
+		hash char(64) not null unique,
+		config jsonb not null,
+		first_applied_at timestamp with time zone not null default now(),
+		last_applied_at timestamp with time zone not null default now()
+	);
+	alter table splash_records
+		add column if not exists config_version integer references config_versions(id);
+	create index if not exists idx_splash_config_version on splash_records (config_version);`
+
+	_, err = DB.Exec(createVersionsPSQL)
+	if err != nil {
+		return fmt.Errorf("failed to create config_versions table: %w", err)
+	}
+	if err := rehashConfigVersions(); err != nil {
+		return err
+	}
+
 	var exists bool
 	checkQuery := `SELECT EXISTS (
 		SELECT FROM information_schema.tables 
@@ -93,7 +199,10 @@ func InitDatabase(dataSourceName string) error {
 	return nil
 }
 
-func GetContextStats(direction string, level int, volume float64, basisGap float64, window int) (total int, wins int, err error) {
+// GetContextStats считает исходы похожих сплешей. Сравниваются только записи с тем же режимом
+// допуска возврата. asOf задает "текущий момент" для отсечения незавершенных окон, чтобы
+// статистика не зависела от часов сервера БД.
+func GetContextStats(direction string, level int, volume float64, basisGap float64, window int, toleranceMode string, asOf time.Time) (total int, wins int, err error) {
 	volMin, volMax := int64(float64(volume)*0.5), int64(float64(volume)*2)
 
 	gapMin, gapMax := basisGap-0.5, basisGap+0.5
@@ -101,16 +210,18 @@ func GetContextStats(direction string, level int, volume float64, basisGap float
 	queryPSQL := `
 	select 
 		count(*) as total,
-		coalesce(sum(case when returned = true then 1 else 0 end), 0) as wins
+		coalesce(sum(case when status = 'RETURNED' and return_time <= time_window * 60 then 1 else 0 end), 0) as wins
 	from splash_records
 	where direction = $1
 		and trigger_level = $2
 		and volume_24h between $3 and $4
 		and basis_gap between $5 and $6
 		and time_window = $7
-		and (returned = true or trigger_time < (now() - (time_window * interval '1 minute')));`
+		and tolerance_mode = $8
+		and status <> 'CANCELLED'
+		and (status in ('RETURNED', 'TIMEOUT') or trigger_time < ($9::timestamptz - (time_window * interval '1 minute')));`
 
-	err = DB.QueryRow(queryPSQL, direction, level, volMin, volMax, gapMin, gapMax, window).Scan(&total, &wins)
+	err = DB.QueryRow(queryPSQL, direction, level, volMin, volMax, gapMin, gapMax, window, toleranceMode, asOf).Scan(&total, &wins)
 
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to select query context stats: %w", err)
@@ -124,9 +235,10 @@ func SaveSplashRecord(r models.SplashRecord, basisGap float64, speedSeconds floa
 		symbol, direction, trigger_level, trigger_time, 
         ref_last_price, ref_fair_price,
         trigger_last_price, trigger_fair_price, 
-        basis_gap, trigger_speed_sec, volume_24h, prob_win, time_window
-	) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) 
-	on conflict (symbol, trigger_level) where (returned = false) do nothing
+        basis_gap, trigger_speed_sec, volume_24h, prob_win, time_window,
+        tolerance_mode, tolerance, level_scale, forced_pin, config_version, data_gap
+	) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, nullif($18, 0), $19) 
+	on conflict (symbol, trigger_level) where (status = 'ACTIVE') do nothing
     returning id;`
 
 	var id int64
@@ -145,7 +257,16 @@ func SaveSplashRecord(r models.SplashRecord, basisGap float64, speedSeconds floa
 		r.Volume24h,
 		r.LongProbability,
 		r.TimeWindow,
+		r.ToleranceMode,
+		r.Tolerance,
+		r.LevelScale,
+		r.ForcedPin,
+		r.ConfigVersion,
+		r.DataGap,
 	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to insert splash record: %s already has an active splash at %d%%", r.Symbol, r.TriggerLevel)
+	}
 	if err != nil {
 		return 0, fmt.Errorf("failed to insert splash record: %w", err)
 	}
@@ -154,36 +275,43 @@ func SaveSplashRecord(r models.SplashRecord, basisGap float64, speedSeconds floa
 	return id, nil
 }
 
-func UpdateSplashRecord(r models.SplashRecord) error {
-	if r.ID == 0 {
+// CloseSplashRecord фиксирует исход сплеша одним UPDATE, не читая запись. Флаг разрыва данных
+// только добавляется к выставленному при срабатывании.
+func CloseSplashRecord(id int64, status string, returnTime time.Duration, maxDeviation float64, dataGap bool) error {
+	if id == 0 {
 		return fmt.Errorf("cannot update splash record with ID 0")
 	}
 
-	returnTimeMs := r.ReturnTime.Seconds()
 	updatePSQL := `
 	update splash_records
 	set returned = $1,
 		return_time = $2,
-		max_deviation = $3
-	where id = $4;`
+		max_deviation = $3,
+		status = $4,
+		data_gap = data_gap or $5
+	where id = $6;`
 
 	_, err := DB.Exec(
 		updatePSQL,
-		r.Returned,
-		returnTimeMs,
-		r.MaxDeviation,
-		r.ID,
+		status == models.StatusReturned,
+		returnTime.Seconds(),
+		maxDeviation,
+		status,
+		dataGap,
+		id,
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to update splash record ID %d: %w", r.ID, err)
+		return fmt.Errorf("failed to update splash record ID %d: %w", id, err)
 	}
 
-	log.Printf("DB: Splash record ID %d updated successfully", r.ID)
+	log.Printf("DB: Splash record ID %d updated successfully", id)
 	return nil
 }
 
-func UpdateSplashLevel(id int64, level int, lastPrice float64, fairPrice float64, volume24 float64, prob_win float64, window int) error {
+// UpdateSplashLevel фиксирует прогрессию сплеша на старший тир. Закрепление только добавляется:
+// сплеш, дошедший до закрепленного тира, остается закрепленным.
+func UpdateSplashLevel(id int64, level int, lastPrice float64, fairPrice float64, volume24 float64, prob_win float64, window int, toleranceMode string, tolerance float64, forcedPin bool) error {
 	updatePSQL := `
 	update splash_records
 	set trigger_level = $1,
@@ -191,8 +319,11 @@ func UpdateSplashLevel(id int64, level int, lastPrice float64, fairPrice float64
 		trigger_fair_price = $3,
 		volume_24h = $4,
 		prob_win = $5,
-		time_window = $6
-	where id = $7;`
+		time_window = $6,
+		tolerance_mode = $7,
+		tolerance = $8,
+		forced_pin = forced_pin or $9
+	where id = $10;`
 
 	_, err := DB.Exec(
 		updatePSQL,
@@ -201,42 +332,120 @@ func UpdateSplashLevel(id int64, level int, lastPrice float64, fairPrice float64
 		fairPrice,
 		volume24,
 		prob_win,
+		window,
+		toleranceMode,
+		tolerance,
+		forcedPin,
 		id,
 	)
 	return err
 }
 
-func GetSplashRecordByID(id int64) (models.SplashRecord, error) {
-	queryPSQL := `
-	select 
+const splashRecordColumns = `
 		id, symbol, direction,
         trigger_level, ref_last_price, ref_fair_price,
         trigger_last_price, trigger_fair_price,
         trigger_time, volume_24h,
-        returned, return_time, max_deviation,
-        prob_win, time_window
-	from splash_records where id = $1;`
+        coalesce(returned, false), coalesce(return_time, 0), coalesce(max_deviation, 0),
+        coalesce(prob_win, 0), time_window, tolerance_mode, coalesce(tolerance, 0), level_scale,
+        status, forced_pin, coalesce(config_version, 0), data_gap`
 
+func scanSplashRecord(row rowScanner) (models.SplashRecord, error) {
 	r := models.SplashRecord{}
-	var returnTime int64
+	var returnTime float64
 
-	err := DB.QueryRow(queryPSQL, id).Scan(
+	err := row.Scan(
 		&r.ID, &r.Symbol, &r.Direction,
 		&r.TriggerLevel, &r.RefLastPrice, &r.RefFairPrice,
 		&r.TriggerLastPrice, &r.TriggerFairPrice,
 		&r.TriggerTime, &r.Volume24h,
-		&r.Returned, &r.ReturnTime, &r.MaxDeviation,
-		&r.LongProbability, &r.TimeWindow,
+		&r.Returned, &returnTime, &r.MaxDeviation,
+		&r.LongProbability, &r.TimeWindow, &r.ToleranceMode, &r.Tolerance, &r.LevelScale,
+		&r.Status, &r.ForcedPin, &r.ConfigVersion, &r.DataGap,
 	)
+	if err != nil {
+		return models.SplashRecord{}, err
+	}
+
+	r.ReturnTime = time.Duration(returnTime * float64(time.Second))
+	return r, nil
+}
 
+// GetActiveSplashRecords возвращает сплеши, оставшиеся в статусе ACTIVE, например после перезапуска приложения.
+// Новые записи идут первыми.
+func GetActiveSplashRecords() ([]models.SplashRecord, error) {
+	queryPSQL := `select ` + splashRecordColumns + ` from splash_records where status = 'ACTIVE' order by trigger_time desc;`
+
+	rows, err := DB.Query(queryPSQL)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return models.SplashRecord{}, fmt.Errorf("record with ID %d not found", id)
+		return nil, fmt.Errorf("failed to select active splash records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []models.SplashRecord
+	for rows.Next() {
+		r, err := scanSplashRecord(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan active splash record: %w", err)
 		}
-		return models.SplashRecord{}, fmt.Errorf("failed to retrieve splash record ID %d: %w", id, err)
+		records = append(records, r)
 	}
+	return records, rows.Err()
+}
 
-	r.ReturnTime = time.Duration(returnTime) * time.Second
+func SavePaperTrade(t models.PaperTrade) (int64, error) {
+	insertPSQL := `
+	insert into paper_trades(
+		record_id, symbol, side, trigger_level, size,
+		entry_price, exit_price, stop_price,
+		entry_time, exit_time, exit_reason,
+		fees, pnl, pnl_percent
+	) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	returning id;`
 
-	return r, nil
+	var id int64
+	err := DB.QueryRow(
+		insertPSQL,
+		t.RecordID,
+		t.Symbol,
+		t.Side,
+		t.Level,
+		t.Size,
+		t.EntryPrice,
+		t.ExitPrice,
+		t.StopPrice,
+		t.EntryTime,
+		t.ExitTime,
+		t.ExitReason,
+		t.Fees,
+		t.PnL,
+		t.PnLPercent,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert paper trade: %w", err)
+	}
+
+	log.Printf("DB: Paper trade saved successfully ID: %d (%s %s PnL %.2f)", id, t.Symbol, t.ExitReason, t.PnL)
+	return id, nil
+}
+
+func GetPaperStats() (models.PaperStats, error) {
+	if DB == nil {
+		return models.PaperStats{}, fmt.Errorf("database is not initialized")
+	}
+
+	queryPSQL := `
+	select
+		count(*),
+		coalesce(sum(case when pnl > 0 then 1 else 0 end), 0),
+		coalesce(sum(pnl), 0),
+		coalesce(avg(pnl), 0)
+	from paper_trades;`
+
+	var stats models.PaperStats
+	err := DB.QueryRow(queryPSQL).Scan(&stats.Trades, &stats.Wins, &stats.TotalPnL, &stats.AvgPnL)
+	if err != nil {
+		return models.PaperStats{}, fmt.Errorf("failed to select paper stats: %w", err)
+	}
+	return stats, nil
 }
@@ -0,0 +1,299 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"splash-trading-bot/lib/models"
+)
+
+// HistoryLimit — сколько прошлых версий профиля хранится.
+const HistoryLimit = 50
+
+// ErrProfileNotFound возвращается, если профиля с таким именем нет (или нет активного профиля).
+var ErrProfileNotFound = errors.New("config profile not found")
+
+func ListConfigProfiles() ([]models.ConfigProfile, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database is not initialized")
+	}
+
+	rows, err := DB.Query(`
+	select id, name, config, is_active, updated_at
+	from config_profiles
+	order by name;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select config profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []models.ConfigProfile
+	for rows.Next() {
+		p, err := scanProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, rows.Err()
+}
+
+func GetConfigProfile(name string) (models.ConfigProfile, error) {
+	if DB == nil {
+		return models.ConfigProfile{}, fmt.Errorf("database is not initialized")
+	}
+
+	row := DB.QueryRow(`
+	select id, name, config, is_active, updated_at
+	from config_profiles where name = $1;`, name)
+	return scanProfile(row)
+}
+
+func GetActiveConfigProfile() (models.ConfigProfile, error) {
+	if DB == nil {
+		return models.ConfigProfile{}, fmt.Errorf("database is not initialized")
+	}
+
+	row := DB.QueryRow(`
+	select id, name, config, is_active, updated_at
+	from config_profiles where is_active
+	order by updated_at desc limit 1;`)
+	return scanProfile(row)
+}
+
+// SaveConfigProfile создает или перезаписывает профиль, делает его активным и кладет версию в историю.
+func SaveConfigProfile(name string, cfg models.EngineConfig) error {
+	if DB == nil {
+		return fmt.Errorf("database is not initialized")
+	}
+
+	encoded, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRow(`
+	insert into config_profiles(name, config, is_active)
+	values ($1, $2, true)
+	on conflict (name) do update
+	set config = excluded.config, is_active = true, updated_at = now()
+	returning id;`, name, encoded).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to save config profile %q: %w", name, err)
+	}
+
+	if _, err = tx.Exec(`update config_profiles set is_active = false where id <> $1 and is_active;`, id); err != nil {
+		return fmt.Errorf("failed to deactivate config profiles: %w", err)
+	}
+
+	if _, err = tx.Exec(`insert into config_profile_history(profile_id, config) values ($1, $2);`, id, encoded); err != nil {
+		return fmt.Errorf("failed to save config history: %w", err)
+	}
+
+	_, err = tx.Exec(`
+	delete from config_profile_history
+	where profile_id = $1 and id not in (
+		select id from config_profile_history where profile_id = $1 order by saved_at desc, id desc limit $2
+	);`, id, HistoryLimit)
+	if err != nil {
+		return fmt.Errorf("failed to trim config history: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ActivateConfigProfile помечает профиль активным, чтобы он загрузился при следующем запуске.
+func ActivateConfigProfile(name string) error {
+	if DB == nil {
+		return fmt.Errorf("database is not initialized")
+	}
+
+	res, err := DB.Exec(`
+	update config_profiles set is_active = (name = $1)
+	where exists (select 1 from config_profiles where name = $1);`, name)
+	if err != nil {
+		return fmt.Errorf("failed to activate config profile %q: %w", name, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrProfileNotFound
+	}
+	return nil
+}
+
+func DeleteConfigProfile(name string) error {
+	if DB == nil {
+		return fmt.Errorf("database is not initialized")
+	}
+
+	res, err := DB.Exec(`delete from config_profiles where name = $1;`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete config profile %q: %w", name, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrProfileNotFound
+	}
+	return nil
+}
+
+// GetConfigHistory возвращает прошлые версии профиля, начиная с последней.
+func GetConfigHistory(name string) ([]models.ConfigRevision, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database is not initialized")
+	}
+
+	rows, err := DB.Query(`
+	select h.id, h.config, h.saved_at
+	from config_profile_history h
+	join config_profiles p on p.id = h.profile_id
+	where p.name = $1
+	order by h.saved_at desc, h.id desc;`, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select config history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []models.ConfigRevision
+	for rows.Next() {
+		var rev models.ConfigRevision
+		var raw []byte
+		if err := rows.Scan(&rev.ID, &raw, &rev.SavedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan config history: %w", err)
+		}
+		if err := json.Unmarshal(raw, &rev.Config); err != nil {
+			return nil, fmt.Errorf("failed to decode config revision %d: %w", rev.ID, err)
+		}
+		history = append(history, rev)
+	}
+	return history, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanProfile(row rowScanner) (models.ConfigProfile, error) {
+	var p models.ConfigProfile
+	var raw []byte
+	if err := row.Scan(&p.ID, &p.Name, &raw, &p.Active, &p.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ConfigProfile{}, ErrProfileNotFound
+		}
+		return models.ConfigProfile{}, fmt.Errorf("failed to scan config profile: %w", err)
+	}
+	if err := json.Unmarshal(raw, &p.Config); err != nil {
+		return models.ConfigProfile{}, fmt.Errorf("failed to decode config profile %q: %w", p.Name, err)
+	}
+	return p, nil
+}
+
+// SaveConfigVersion регистрирует примененную конфигурацию и возвращает id ее версии.
+// Повторное применение той же конфигурации обновляет только last_applied_at.
+// Хранится только детекционная часть, без вебхуков и их секретов.
+func SaveConfigVersion(hash string, cfg models.EngineConfig) (int64, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("database is not initialized")
+	}
+
+	encoded, err := json.Marshal(cfg.Detection())
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	var id int64
+	err = DB.QueryRow(`
+	insert into config_versions(hash, config)
+	values ($1, $2)
+	on conflict (hash) do update set last_applied_at = now()
+	returning id;`, hash, encoded).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save config version: %w", err)
+	}
+	return id, nil
+}
+
+// rehashConfigVersions приводит версии, сохраненные до перехода на детекционный отпечаток, к новому виду:
+// из конфигурации убираются вебхуки и бумажная торговля, хеш пересчитывается через Fingerprint.
+// Если версия с таким хешем уже есть, старая сливается с ней: сплеши переносятся, интервал применения объединяется.
+func rehashConfigVersions() error {
+	rows, err := DB.Query(`select id, hash, config from config_versions order by id`)
+	if err != nil {
+		return fmt.Errorf("failed to load config versions: %w", err)
+	}
+	type version struct {
+		id      int64
+		hash    string
+		encoded []byte
+	}
+	var stale []version
+	for rows.Next() {
+		var v version
+		var raw []byte
+		if err := rows.Scan(&v.id, &v.hash, &raw); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan config version: %w", err)
+		}
+		var cfg models.EngineConfig
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to decode config version %d: %w", v.id, err)
+		}
+		if hash := cfg.Fingerprint(); hash != v.hash {
+			v.hash = hash
+			v.encoded, _ = json.Marshal(cfg.Detection())
+			stale = append(stale, v)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to load config versions: %w", err)
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start config version migration: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, v := range stale {
+		var keeper int64
+		err := tx.QueryRow(`select id from config_versions where hash = $1 and id <> $2`, v.hash, v.id).Scan(&keeper)
+		switch {
+		case err == sql.ErrNoRows:
+			_, err = tx.Exec(`update config_versions set hash = $1, config = $2 where id = $3`, v.hash, v.encoded, v.id)
+		case err == nil:
+			_, err = tx.Exec(`update splash_records set config_version = $1 where config_version = $2`, keeper, v.id)
+			if err == nil {
+				_, err = tx.Exec(`
+				update config_versions k
+				set first_applied_at = least(k.first_applied_at, d.first_applied_at),
+					last_applied_at = greatest(k.last_applied_at, d.last_applied_at)
+				from config_versions d
+				where k.id = $1 and d.id = $2`, keeper, v.id)
+			}
+			if err == nil {
+				_, err = tx.Exec(`delete from config_versions where id = $1`, v.id)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to migrate config version %d: %w", v.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit config version migration: %w", err)
+	}
+	log.Printf("DB: %d config versions rehashed", len(stale))
+	return nil
+}
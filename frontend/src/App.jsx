@@ -1,13 +1,13 @@
-import React, { useState, useEffect, useRef } from 'react';
+import React, { useState, useEffect } from 'react';
 import { motion, AnimatePresence } from 'framer-motion'; 
-import { BarChart, Settings, ExternalLink, Plus, Trash2, Zap, Clock, Database, Pin, PinOff, AlertTriangle } from 'lucide-react';
+import { BarChart, Settings, ExternalLink, Plus, Trash2, Zap, Clock, Database, Pin, PinOff, AlertTriangle, Wallet, Circle, X, Play, Pause, Square } from 'lucide-react';
 import { EventsOn, BrowserOpenURL } from '../wailsjs/runtime/runtime';
-import { UpdateConfig } from '../wailsjs/go/app/App';
+import { UpdateConfig, GetConfig, GetPaperStats, StartRecording, StopRecording, IsRecording, DismissSplash, ActiveProfile, ListProfiles, LoadProfile, SaveProfile, DeleteProfile, GetProfileHistory, StartEngine, StopEngine, PauseEngine, ResumeEngine, EngineState, GetFeedHealth } from '../wailsjs/go/app/App';
 
-const TierInput = ({ value, onChange }) => {
+const TierInput = ({ value, onChange, maxLength = 5 }) => {
   const [displayValue, setDisplayValue] = useState((value || 0).toString());
   useEffect(() => { setDisplayValue((value || 0).toString()); }, [value]);
-  const handleChange = (e) => { if (e.target.value.length > 5) return; setDisplayValue(e.target.value); };
+  const handleChange = (e) => { if (e.target.value.length > maxLength) return; setDisplayValue(e.target.value); };
   const handleBlur = () => {
     const sanitized = displayValue.replace(',', '.');
     let finalValue = parseFloat(sanitized);
@@ -17,7 +17,7 @@ const TierInput = ({ value, onChange }) => {
   return ( <input type="text" value={displayValue} onChange={handleChange} onBlur={handleBlur} className="w-full bg-black/50 border border-white/10 rounded-sm p-1 text-xs text-blue-400 font-bold" /> );
 };
 
-const SignalCard = ({ signal }) => {
+const SignalCard = ({ signal, onDismiss }) => {
   const isReturned = signal.status === 'RETURNED';
   const isTimeout = signal.status === 'TIMEOUT';
   const getWinRateColor = (prob) => {
@@ -48,6 +48,10 @@ const SignalCard = ({ signal }) => {
         <div key={isReturned || isTimeout ? 'del' : signal.level} className={`absolute bottom-0 left-0 h-0.5 animate-shrink-width ${isReturned ? 'bg-green-900' : isTimeout ? 'bg-red-900' : 'bg-green-400'}`}></div>
       )}
 
+      {signal.isForcedPin && (
+        <button onClick={() => onDismiss(signal)} title="Dismiss" className="absolute right-1 top-1 p-1 text-slate-600 hover:text-red-400 transition-all"><X size={12} /></button>
+      )}
+
       <div className="flex justify-between items-start">
         <div className="flex flex-col gap-1">
           <div className="flex items-center gap-2">
@@ -55,6 +59,10 @@ const SignalCard = ({ signal }) => {
             <span className={`text-[10px] font-bold px-1.5 py-0.5 rounded border ${signal.direction === 'UP' ? 'text-blue-400 border-blue-500/30' : 'text-red-400 border-red-500/30'}`}>
               {signal.direction} {signal.level}%
             </span>
+            {signal.isForcedPin && <Pin size={12} className="text-blue-400" />}
+            {signal.dataGap && (
+               <span title="Feed gap near this splash: reference history or tracking is incomplete" className="text-[10px] border border-amber-500/50 text-amber-400 px-1 rounded uppercase font-black tracking-tighter flex items-center gap-1"><AlertTriangle size={10}/> Gap</span>
+            )}
             {signal.isProgression && !isReturned && !isTimeout && (
                <span className="text-[10px] bg-blue-600 text-white px-1 rounded animate-pulse uppercase font-black tracking-tighter">Progression</span>
             )}
@@ -63,6 +71,7 @@ const SignalCard = ({ signal }) => {
              <span className="flex items-center gap-1"><Database size={12}/> VOL24: {signal.volume ? (signal.volume / 1000000).toFixed(1) : "0"}M</span>
              <span className="flex items-center gap-1 opacity-40"><Clock size={12}/> {signal.timestamp}</span>
              <span className="text-slate-400 border-l border-white/10 pl-2">Time Window: {signal.activeWindow}m</span>
+             {signal.lookback > 0 && <span className="text-slate-400 border-l border-white/10 pl-2">Lookback: {signal.lookback}m</span>}
           </div>
         </div>
         <div className="text-right">
@@ -90,6 +99,12 @@ const SignalCard = ({ signal }) => {
          <div className="flex gap-4 text-[10px] font-mono font-bold">
             <div className="flex flex-col"><span className="text-[8px] text-slate-600 uppercase font-bold">Basis Gap</span><span className="text-purple-400 font-bold">+{signal.gap}%</span></div>
             <div className="flex flex-col"><span className="text-[8px] text-slate-600 uppercase font-bold">Speed</span><span className="text-yellow-500 font-bold">{signal.speed}s</span></div>
+            {signal.tolerance && (
+              <div className="flex flex-col"><span className="text-[8px] text-slate-600 uppercase font-bold">Return Tol</span><span className="text-slate-300 font-bold">{signal.tolerance}%</span></div>
+            )}
+            {signal.scale && parseFloat(signal.scale) !== 1 && (
+              <div className="flex flex-col"><span className="text-[8px] text-slate-600 uppercase font-bold">Vol Scale</span><span className="text-orange-400 font-bold">x{signal.scale}</span></div>
+            )}
          </div>
          <button onClick={() => BrowserOpenURL(`https://www.mexc.com/ru-RU/futures/${signal.symbol}?type=futures`)}
            className="px-4 py-1.5 rounded bg-blue-600/10 text-blue-500 hover:bg-blue-600 hover:text-white transition-all border border-blue-500/20 text-[9px] font-black uppercase flex items-center gap-2">
@@ -97,6 +112,19 @@ const SignalCard = ({ signal }) => {
          </button>
       </div>
 
+      {signal.paper && (
+        <div className="flex justify-between items-center bg-black/40 px-2.5 py-1.5 rounded border border-white/5 text-[10px] font-mono font-bold">
+          <span className="flex items-center gap-1 text-slate-500 uppercase"><Wallet size={12}/> Paper {signal.paper.side} @ {signal.paper.entryPrice}</span>
+          {signal.paper.status === 'CLOSED' ? (
+            <span className={parseFloat(signal.paper.pnl) >= 0 ? 'text-green-400' : 'text-red-400'}>
+              {signal.paper.exitReason} | {signal.paper.pnl} USDT ({signal.paper.pnlPercent}%)
+            </span>
+          ) : (
+            <span className="text-slate-500">SL {signal.paper.stopPrice}</span>
+          )}
+        </div>
+      )}
+
       <AnimatePresence>
         {(isReturned || isTimeout) && (
           <motion.div initial={{ height: 0, opacity: 0 }} animate={{ height: "auto", opacity: 1 }} 
@@ -121,15 +149,25 @@ const App = () => {
     { level: 5, window: 15, isForcedPin: false } 
   ]);
 
-  const configsRef = useRef(splashConfigs);
-  useEffect(() => { configsRef.current = splashConfigs; }, [splashConfigs]);
+  const [lookbackWindow, setLookbackWindow] = useState(5);
+  const [staleAfter, setStaleAfter] = useState(10);
+  const [webhooks, setWebhooks] = useState([]);
+  const [overrides, setOverrides] = useState([]);
+  const [adaptive, setAdaptive] = useState({ enabled: false, baseline: 0.3, minScale: 0.5, maxScale: 3 });
+  const [sanity, setSanity] = useState({ maxJump: 30, medianWindow: 3, confirmUpdates: 0 });
+  const [filter, setFilter] = useState({ include: [], exclude: [], includeRegex: [], excludeRegex: ['^(USDC|FDUSD|TUSD|DAI)_USDT$'], minVolume: 0 });
+  const [paperConfig, setPaperConfig] = useState({ positionSize: 100, feeRate: 0.02, slippage: 0.05, stopLoss: 3 });
+  const [paperStats, setPaperStats] = useState({ trades: 0, wins: 0, totalPnl: 0 });
+  const [isRecording, setIsRecording] = useState(false);
+  const [engineState, setEngineState] = useState('STOPPED');
+  const [feedHealth, setFeedHealth] = useState({ status: 'CONNECTING', consecutiveFailures: 0, latencyMs: 0, lastError: '' });
+  const [profileName, setProfileName] = useState('default');
+  const [profiles, setProfiles] = useState([]);
+  const [history, setHistory] = useState([]);
+  const [configErrors, setConfigErrors] = useState([]);
 
-  const getApplicableTier = (lvl) => {
-    const configs = configsRef.current;
-    if (!configs || configs.length === 0) return null;
-    const sorted = [...configs].sort((a, b) => a.level - b.level);
-    return sorted.filter(c => parseFloat(lvl) >= c.level).pop() || null;
-  };
+  // Тир берем из события: движок уже учел переопределения по символу и объему
+  const tierFromEvent = (data) => ({ level: data.level, window: data.activeWindow, isForcedPin: !!data.isForcedPin });
 
   useEffect(() => {
     const timer = setInterval(() => {
@@ -141,9 +179,8 @@ const App = () => {
             hasChanges = true;
             return { ...s, isPinned: false, unpinAt: null };
           }
-          if (s.status === 'ACTIVE') {
-            const tier = getApplicableTier(s.level);
-            const limit = (tier?.window || s.activeWindow || 5) * 60 * 1000;
+          if (s.status === 'ACTIVE' && !s.isForcedPin) {
+            const limit = (s.activeWindow || 5) * 60 * 1000;
             if (now - s.createdAt > limit) {
               hasChanges = true;
               return { ...s, status: 'TIMEOUT', deleteAt: now + 10000, isPinned: false, unpinAt: null };
@@ -165,25 +202,32 @@ const App = () => {
 
   const handleNewSignal = (data) => {
     setSignals(prev => {
-      const existingIdx = prev.findIndex(s => s.symbol === data.symbol);
+      const activeIdx = prev.findIndex(s => s.symbol === data.symbol && s.status === 'ACTIVE');
+      const existingIdx = activeIdx !== -1 ? activeIdx : prev.findIndex(s => s.symbol === data.symbol);
       const now = Date.now();
 
+      if (data.status === 'DISMISSED' || data.status === 'CANCELLED') {
+        return prev.filter(s => !(s.symbol === data.symbol && s.status === 'ACTIVE'));
+      }
+
       if (data.status === 'RETURNED' || data.status === 'TIMEOUT') {
         if (existingIdx === -1) return prev;
         const existing = prev[existingIdx];
+        // закрепленная карточка остается на экране, пока ее не снимут вручную
+        if (existing.isForcedPin) {
+          return prev.map((s, i) => i === existingIdx ? { ...s, ...data, isPinned: true, unpinAt: null } : s);
+        }
         const updated = { ...existing, ...data, isPinned: false, deleteAt: now + 10000, unpinAt: null };
         return [...prev.filter((_, i) => i !== existingIdx), updated];
       }
 
-      const currentTier = getApplicableTier(data.level);
-      if (!currentTier) return prev; 
+      const currentTier = tierFromEvent(data);
 
-      if (existingIdx !== -1) {
+      if (existingIdx !== -1 && (prev[existingIdx].status === 'ACTIVE' || !prev[existingIdx].isForcedPin)) {
         const existing = prev[existingIdx];
         if (existing.status !== 'ACTIVE' || data.direction !== existing.direction) return prev;
 
-        const existingTier = getApplicableTier(existing.level);
-       if (currentTier && existingTier && currentTier.level > existingTier.level) {
+        if (currentTier.level > existing.level) {
           const updated = {
             ...existing,
             ...data,
@@ -191,27 +235,133 @@ const App = () => {
             activeWindow: currentTier.window, 
             createdAt: Date.now(),          
             isPinned: (data.prob > 60 || currentTier.isForcedPin),
-            unpinAt: (data.prob > 60 || currentTier.isForcedPin) ? now + 10000 : null,
+            unpinAt: currentTier.isForcedPin ? null : (data.prob > 60 ? now + 10000 : null),
           };
           const filtered = prev.filter((_, i) => i !== existingIdx);
           return [updated, ...filtered];
         }
       }
 
-      const newSig = { ...data, id: `sig-${data.symbol}-${now}`, isPinned: (data.prob > 60 || currentTier.isForcedPin), unpinAt: (data.prob > 60 || currentTier.isForcedPin) ? now + 10000 : null, createdAt: now, activeWindow: currentTier.window, status: 'ACTIVE' };
+      const newSig = { ...data, id: `sig-${data.symbol}-${now}`, isPinned: (data.prob > 60 || currentTier.isForcedPin), unpinAt: currentTier.isForcedPin ? null : (data.prob > 60 ? now + 10000 : null), createdAt: now, activeWindow: currentTier.window, status: 'ACTIVE' };
       return [newSig, ...prev].slice(0, 50);
     });
   };
 
+  const dismissSignal = (signal) => {
+    if (signal.status === 'ACTIVE') {
+      DismissSplash(signal.symbol).catch(err => console.error(err));
+    }
+    setSignals(prev => prev.filter(s => s.id !== signal.id));
+  };
+
+  const handlePaperTrade = (data) => {
+    setSignals(prev => prev.map(s => s.symbol === data.symbol ? { ...s, paper: data } : s));
+    if (data.status === 'CLOSED') {
+      const pnl = parseFloat(data.pnl);
+      setPaperStats(prev => ({ trades: prev.trades + 1, wins: prev.wins + (pnl > 0 ? 1 : 0), totalPnl: prev.totalPnl + pnl }));
+    }
+  };
+
   useEffect(() => {
     const unsubscribe = EventsOn("splash:new", (data) => handleNewSignal(data));
-    UpdateConfig({ tiers: splashConfigs });
-    return () => unsubscribe();
+    const unsubscribePaper = EventsOn("paper:trade", (data) => handlePaperTrade(data));
+    const unsubscribeEngine = EventsOn("engine:state", (state) => setEngineState(state));
+    EngineState().then(setEngineState).catch(() => {});
+    const unsubscribeFeed = EventsOn("feed:health", (health) => setFeedHealth(health));
+    GetFeedHealth().then(h => h && setFeedHealth(h)).catch(() => {});
+    GetConfig().then(applyConfig).catch(err => console.error(err));
+    ActiveProfile().then(setProfileName).catch(() => {});
+    refreshProfiles();
+    GetPaperStats().then(stats => stats && setPaperStats(stats)).catch(() => {});
+    IsRecording().then(setIsRecording).catch(() => {});
+    return () => { unsubscribe(); unsubscribePaper(); unsubscribeEngine(); unsubscribeFeed(); };
   }, []);
 
+  // Бэкенд отдает nil-слайсы как null, поэтому поля нормализуются перед записью в состояние
+  const applyConfig = (cfg) => {
+    if (!cfg) return;
+    const f = cfg.filter || {};
+    setLookbackWindow(cfg.window || 5);
+    setStaleAfter(cfg.staleAfter || 10);
+    setSplashConfigs(cfg.tiers || []);
+    setOverrides((cfg.overrides || []).map(o => ({ ...o, symbols: o.symbols || [], patterns: o.patterns || [], tiers: o.tiers || [] })));
+    setFilter({ include: f.include || [], exclude: f.exclude || [], includeRegex: f.includeRegex || [], excludeRegex: f.excludeRegex || [], minVolume: f.minVolume || 0 });
+    if (cfg.adaptive) setAdaptive(cfg.adaptive);
+    if (cfg.sanity) setSanity(cfg.sanity);
+    setWebhooks(cfg.webhooks || []);
+    if (cfg.paper) setPaperConfig(cfg.paper);
+  };
+
+  const currentConfig = () => ({ window: lookbackWindow, staleAfter, tiers: splashConfigs, overrides, filter, adaptive, sanity, webhooks, paper: paperConfig });
+
+  const refreshProfiles = () => {
+    ListProfiles().then(list => setProfiles(list || [])).catch(() => setProfiles([]));
+  };
+
+  const showHistory = (name) => {
+    GetProfileHistory(name).then(list => setHistory(list || [])).catch(() => setHistory([]));
+  };
+
+  const loadProfile = (name) => {
+    LoadProfile(name).then(cfg => { applyConfig(cfg); setProfileName(name); setHistory([]); setConfigErrors([]); refreshProfiles(); })
+      .catch(err => setConfigErrors([{ field: 'profile', message: String(err) }]));
+  };
+
+  const saveProfile = () => {
+    SaveProfile(profileName, currentConfig()).then(errs => {
+      setConfigErrors(errs || []);
+      if (!errs || errs.length === 0) refreshProfiles();
+    }).catch(err => setConfigErrors([{ field: 'profile', message: String(err) }]));
+  };
+
+  const deleteProfile = (name) => {
+    DeleteProfile(name).then(refreshProfiles).catch(err => console.error(err));
+  };
+
+  const toggleRecording = () => {
+    const action = isRecording ? StopRecording() : StartRecording('');
+    action.then(() => setIsRecording(!isRecording)).catch(err => console.error(err));
+  };
+
+  const feedColor = (status) => {
+    if (status === 'OK') return 'text-emerald-400';
+    if (status === 'DEGRADED' || status === 'RATE_LIMITED') return 'text-amber-400';
+    if (status === 'DOWN') return 'text-red-400';
+    return 'text-slate-500';
+  };
+
+  // Состояние приходит событием engine:state, поэтому после вызова его не нужно выставлять вручную
+  const engineAction = (action) => {
+    action().catch(err => console.error(err));
+  };
+
+  const updateWebhook = (idx, field, value) => {
+    const n = [...webhooks];
+    n[idx] = { ...n[idx], [field]: value };
+    setWebhooks(n);
+  };
+
+  const splitList = (value) => value === '' ? [] : value.split(',').map(x => x.trim());
+
+  const splitLines = (value) => value === '' ? [] : value.split('\n');
+
+  const updateOverride = (idx, field, value) => {
+    const n = [...overrides];
+    n[idx] = { ...n[idx], [field]: value };
+    setOverrides(n);
+  };
+
+  const updateOverrideTier = (idx, tierIdx, field, value) => {
+    const tiers = [...overrides[idx].tiers];
+    tiers[tierIdx] = { ...tiers[tierIdx], [field]: value };
+    updateOverride(idx, 'tiers', tiers);
+  };
+
   const saveStrategy = () => {
-    UpdateConfig({ tiers: splashConfigs });
-    setIsSettingsOpen(false);
+    UpdateConfig(currentConfig()).then(errs => {
+      setConfigErrors(errs || []);
+      if (!errs || errs.length === 0) setIsSettingsOpen(false);
+    }).catch(err => console.error(err));
   };
 
   const displaySignals = [...signals].sort((a, b) => {
@@ -226,13 +376,30 @@ const App = () => {
     <div className="h-screen w-full bg-[#161515] text-[#e0e0e0] font-mono flex flex-col overflow-hidden">
       <header className="h-14 border-b border-white/5 bg-[#080808] flex items-center justify-between px-6 z-20 shrink-0">
         <div className="flex items-center gap-2 text-slate-100 font-black italic tracking-tighter text-xl"><BarChart size={20} /> Terminus</div>
-        <button onClick={() => setIsSettingsOpen(!isSettingsOpen)} className="hover:bg-white/10 px-4 py-2 border border-white/10 text-[10px] font-black uppercase flex items-center gap-2"><Settings size={14}/> Config</button>
+        <div className="flex items-center gap-2">
+          <span title={feedHealth.lastError || ''} className={`text-[10px] font-black uppercase px-2 border-r border-white/10 ${feedColor(feedHealth.status)}`}>
+            Feed {feedHealth.status}{feedHealth.status === 'OK' ? ` · ${feedHealth.latencyMs}ms` : feedHealth.consecutiveFailures > 0 ? ` · ${feedHealth.consecutiveFailures} err` : ''}
+          </span>
+          <span className={`text-[10px] font-black uppercase px-2 ${engineState === 'RUNNING' ? 'text-emerald-400' : engineState === 'PAUSED' ? 'text-amber-400' : 'text-slate-500'}`}>{engineState}</span>
+          {engineState !== 'STOPPED' && (
+            <button onClick={() => engineAction(engineState === 'PAUSED' ? ResumeEngine : PauseEngine)} className="px-4 py-2 border border-white/10 hover:bg-white/10 text-[10px] font-black uppercase flex items-center gap-2">
+              {engineState === 'PAUSED' ? <><Play size={12}/> Resume</> : <><Pause size={12}/> Pause</>}
+            </button>
+          )}
+          <button onClick={() => engineAction(engineState === 'STOPPED' ? StartEngine : StopEngine)} className="px-4 py-2 border border-white/10 hover:bg-white/10 text-[10px] font-black uppercase flex items-center gap-2">
+            {engineState === 'STOPPED' ? <><Play size={12}/> Start</> : <><Square size={12}/> Stop</>}
+          </button>
+          <button onClick={toggleRecording} className={`px-4 py-2 border text-[10px] font-black uppercase flex items-center gap-2 ${isRecording ? 'border-red-500/50 text-red-400 bg-red-500/10' : 'border-white/10 hover:bg-white/10'}`}>
+            <Circle size={10} className={isRecording ? 'fill-red-500 animate-pulse' : ''}/> Rec
+          </button>
+          <button onClick={() => setIsSettingsOpen(!isSettingsOpen)} className="hover:bg-white/10 px-4 py-2 border border-white/10 text-[10px] font-black uppercase flex items-center gap-2"><Settings size={14}/> Config</button>
+        </div>
       </header>
 
       <main className="flex-1 flex overflow-hidden relative">
         <motion.div layout transition={{ duration: 0.4 }} className="flex-1 overflow-y-auto p-4 custom-scrollbar space-y-3">
           <AnimatePresence mode="popLayout">
-            {displaySignals.map((sig) => ( <SignalCard key={sig.id} signal={sig} /> ))}
+            {displaySignals.map((sig) => ( <SignalCard key={sig.id} signal={sig} onDismiss={dismissSignal} /> ))}
           </AnimatePresence>
         </motion.div>
 
@@ -240,25 +407,193 @@ const App = () => {
           {isSettingsOpen && (
             <motion.aside initial={{ x: "100%", width: 0 }} animate={{ x: 0, width: 380 }} exit={{ x: "100%", width: 0 }} 
                transition={{ type: 'spring', damping: 30, stiffness: 300 }}
-               className="border-l border-white/5 bg-[#080808] p-6 flex flex-col gap-6 z-30 shrink-0 overflow-hidden"
+               className="border-l border-white/5 bg-[#080808] p-6 flex flex-col gap-6 z-30 shrink-0 overflow-x-hidden overflow-y-auto custom-scrollbar"
             >
+              <h2 className="text-[10px] font-black uppercase text-blue-500 italic tracking-widest">Profiles</h2>
+              <section className="space-y-2">
+                <div className="grid grid-cols-[1fr_60px] gap-2 items-end">
+                  <div><label className="text-[10px] text-slate-500 uppercase font-black">Profile Name</label>
+                       <input type="text" value={profileName} onChange={(e) => setProfileName(e.target.value)} className="w-full bg-black/50 border border-white/10 rounded-sm p-1 text-xs text-blue-400 font-bold" /></div>
+                  <button onClick={saveProfile} className="h-7 border border-white/10 text-[9px] font-black uppercase hover:bg-white/10">Save</button>
+                </div>
+                {profiles.map(p => (
+                  <div key={p.name} className="flex items-center justify-between text-[10px] font-bold">
+                    <button onClick={() => loadProfile(p.name)} className={`uppercase ${p.active ? 'text-blue-400' : 'text-slate-400 hover:text-white'}`}>{p.name}{p.active ? ' •' : ''}</button>
+                    <div className="flex gap-2">
+                      <button onClick={() => showHistory(p.name)} className="text-slate-600 hover:text-white uppercase"><Clock size={10} /></button>
+                      {!p.active && <button onClick={() => deleteProfile(p.name)} className="text-slate-600 hover:text-red-500"><Trash2 size={10} /></button>}
+                    </div>
+                  </div>
+                ))}
+                {history.length > 0 && (
+                  <div className="bg-white/5 p-2 border border-white/5 rounded-sm space-y-1 max-h-32 overflow-y-auto custom-scrollbar">
+                    {history.map(rev => (
+                      <button key={rev.id} onClick={() => applyConfig(rev.config)} title="Load into editor"
+                        className="w-full text-left text-[9px] text-slate-500 hover:text-white font-bold">{new Date(rev.savedAt).toLocaleString()} — {(rev.config.tiers || []).length} tiers</button>
+                    ))}
+                  </div>
+                )}
+              </section>
               <h2 className="text-[10px] font-black uppercase text-blue-500 italic tracking-widest">Splash Strategy</h2>
               <section className="space-y-4">
+                <div className="grid grid-cols-[1fr_2fr] gap-4 items-end">
+                  <div><label className="text-[10px] text-slate-500 uppercase font-black">Lookback (m)</label>
+                       <TierInput value={lookbackWindow} onChange={(v) => setLookbackWindow(v)} /></div>
+                  <div className="text-[9px] text-slate-600 uppercase font-bold pb-1">Default reference window, tiers may override</div>
+                </div>
+                <div className="grid grid-cols-[1fr_2fr] gap-4 items-end">
+                  <div><label className="text-[10px] text-slate-500 uppercase font-black">Stale after (s)</label>
+                       <TierInput value={staleAfter} onChange={(v) => setStaleAfter(v)} /></div>
+                  <div className="text-[9px] text-slate-600 uppercase font-bold pb-1">Symbol silent this long: price history resets, nearby splashes flagged</div>
+                </div>
                 {splashConfigs.map((cfg, idx) => (
                   <div key={idx} className="bg-white/5 p-3 border border-white/5 rounded-sm relative group">
-                    <div className="grid grid-cols-[1fr_1fr_40px] gap-4 items-end">
+                    <div className="grid grid-cols-[1fr_1fr_40px_40px] gap-4 items-end">
                         <div><label className="text-[10px] text-slate-500 uppercase font-black">Level (%)</label>
                              <TierInput value={cfg.level} onChange={(v) => { const n = [...splashConfigs]; n[idx].level = v; setSplashConfigs(n); }} /></div>
                         <div><label className="text-[10px] text-slate-500 uppercase font-black">Window (m)</label>
                              <TierInput value={cfg.window} onChange={(v) => { const n = [...splashConfigs]; n[idx].window = v; setSplashConfigs(n); }} /></div>
                         <button onClick={() => { const n = [...splashConfigs]; n[idx].isForcedPin = !n[idx].isForcedPin; setSplashConfigs(n); }}
                           className={`h-7 w-full flex items-center justify-center rounded border transition-all ${cfg.isForcedPin ? 'bg-blue-600/20 border-blue-500 text-blue-400 shadow-md' : 'bg-black/40 border-white/10 text-slate-600'}`}>{cfg.isForcedPin ? <Pin size={14} /> : <PinOff size={14} />}</button>
+                        <button onClick={() => { const n = [...splashConfigs]; n[idx].paperTrade = !n[idx].paperTrade; setSplashConfigs(n); }} title="Paper trade"
+                          className={`h-7 w-full flex items-center justify-center rounded border transition-all ${cfg.paperTrade ? 'bg-green-600/20 border-green-500 text-green-400 shadow-md' : 'bg-black/40 border-white/10 text-slate-600'}`}><Wallet size={14} /></button>
+                    </div>
+                    <div className="grid grid-cols-[1fr_1fr_1fr_1fr] gap-4 items-end mt-2">
+                        <div><label className="text-[10px] text-slate-500 uppercase font-black">Direction</label>
+                             <select value={cfg.direction || 'BOTH'} onChange={(e) => { const n = [...splashConfigs]; n[idx].direction = e.target.value; setSplashConfigs(n); }}
+                               className="w-full bg-black/50 border border-white/10 rounded-sm p-1 text-xs text-blue-400 font-bold">
+                               <option value="BOTH">Both</option>
+                               <option value="UP">Pump</option>
+                               <option value="DOWN">Dump</option>
+                             </select></div>
+                        <div><label className="text-[10px] text-slate-500 uppercase font-black">Lookback (m)</label>
+                             <TierInput value={cfg.lookback} onChange={(v) => { const n = [...splashConfigs]; n[idx].lookback = v; setSplashConfigs(n); }} /></div>
+                        <div><label className="text-[10px] text-slate-500 uppercase font-black">Return Tol.</label>
+                             <select value={cfg.toleranceMode || 'auto'} onChange={(e) => { const n = [...splashConfigs]; n[idx].toleranceMode = e.target.value; setSplashConfigs(n); }}
+                               className="w-full bg-black/50 border border-white/10 rounded-sm p-1 text-xs text-blue-400 font-bold">
+                               <option value="auto">Auto</option>
+                               <option value="percent">Percent</option>
+                               <option value="fraction">Splash Fraction</option>
+                               <option value="atr">ATR x</option>
+                             </select></div>
+                        <div><label className="text-[10px] text-slate-500 uppercase font-black">Value</label>
+                             <TierInput value={cfg.tolerance} onChange={(v) => { const n = [...splashConfigs]; n[idx].tolerance = v; setSplashConfigs(n); }} /></div>
                     </div>
                     <button onClick={() => setSplashConfigs(splashConfigs.filter((_, i) => i !== idx))} className="absolute -right-2 -top-2 bg-red-900/80 p-1 rounded-full text-white opacity-0 group-hover:opacity-100 transition-all"><Trash2 size={10}/></button>
                   </div>
                 ))}
                 <button onClick={() => setSplashConfigs([...splashConfigs, { level: 5, window: 5, isForcedPin: false }])} className="w-full py-2 border border-dashed border-white/10 text-slate-500 text-[9px] uppercase font-black hover:bg-white/5 transition-all tracking-widest">+ New Tier</button>
               </section>
+              <h2 className="text-[10px] font-black uppercase text-blue-500 italic tracking-widest">Volatility Scaling</h2>
+              <section className="grid grid-cols-[40px_1fr_1fr_1fr] gap-2 items-end">
+                <button onClick={() => setAdaptive({ ...adaptive, enabled: !adaptive.enabled })} title="Scale tier levels by symbol volatility"
+                  className={`h-7 w-full flex items-center justify-center rounded border transition-all text-[9px] font-black ${adaptive.enabled ? 'bg-orange-600/20 border-orange-500 text-orange-400 shadow-md' : 'bg-black/40 border-white/10 text-slate-600'}`}>{adaptive.enabled ? 'ON' : 'OFF'}</button>
+                <div><label className="text-[10px] text-slate-500 uppercase font-black">Base Vol %/m</label>
+                     <TierInput value={adaptive.baseline} onChange={(v) => setAdaptive({ ...adaptive, baseline: v })} /></div>
+                <div><label className="text-[10px] text-slate-500 uppercase font-black">Min x</label>
+                     <TierInput value={adaptive.minScale} onChange={(v) => setAdaptive({ ...adaptive, minScale: v })} /></div>
+                <div><label className="text-[10px] text-slate-500 uppercase font-black">Max x</label>
+                     <TierInput value={adaptive.maxScale} onChange={(v) => setAdaptive({ ...adaptive, maxScale: v })} /></div>
+              </section>
+              <h2 className="text-[10px] font-black uppercase text-blue-500 italic tracking-widest">Bad Tick Filter</h2>
+              <section className="grid grid-cols-3 gap-2 items-end">
+                <div><label className="text-[10px] text-slate-500 uppercase font-black" title="Larger jumps from the recent median need confirmation, 0 disables">Max jump %</label>
+                     <TierInput value={sanity.maxJump} onChange={(v) => setSanity({ ...sanity, maxJump: v })} /></div>
+                <div><label className="text-[10px] text-slate-500 uppercase font-black" title="Recent prints kept for the median, 0 = 3">Median of</label>
+                     <TierInput value={sanity.medianWindow} onChange={(v) => setSanity({ ...sanity, medianWindow: v })} /></div>
+                <div><label className="text-[10px] text-slate-500 uppercase font-black" title="Consecutive updates a splash must hold before firing">Confirm</label>
+                     <TierInput value={sanity.confirmUpdates} onChange={(v) => setSanity({ ...sanity, confirmUpdates: v })} /></div>
+              </section>
+              <h2 className="text-[10px] font-black uppercase text-blue-500 italic tracking-widest">Symbol Filter</h2>
+              <section className="space-y-2">
+                <div className="grid grid-cols-2 gap-4">
+                  <div><label className="text-[10px] text-slate-500 uppercase font-black">Include</label>
+                       <input type="text" value={filter.include.join(',')} onChange={(e) => setFilter({ ...filter, include: splitList(e.target.value) })} placeholder="all symbols" className="w-full bg-black/50 border border-white/10 rounded-sm p-1 text-xs text-blue-400 font-bold" /></div>
+                  <div><label className="text-[10px] text-slate-500 uppercase font-black">Exclude</label>
+                       <input type="text" value={filter.exclude.join(',')} onChange={(e) => setFilter({ ...filter, exclude: splitList(e.target.value) })} className="w-full bg-black/50 border border-white/10 rounded-sm p-1 text-xs text-blue-400 font-bold" /></div>
+                </div>
+                <div className="grid grid-cols-[1fr_1fr_80px] gap-4 items-end">
+                  <div><label className="text-[10px] text-slate-500 uppercase font-black">Include Regex</label>
+                       <textarea rows={2} value={filter.includeRegex.join('\n')} onChange={(e) => setFilter({ ...filter, includeRegex: splitLines(e.target.value) })} className="w-full bg-black/50 border border-white/10 rounded-sm p-1 text-xs text-blue-400 font-bold resize-none" /></div>
+                  <div><label className="text-[10px] text-slate-500 uppercase font-black">Exclude Regex</label>
+                       <textarea rows={2} value={filter.excludeRegex.join('\n')} onChange={(e) => setFilter({ ...filter, excludeRegex: splitLines(e.target.value) })} className="w-full bg-black/50 border border-white/10 rounded-sm p-1 text-xs text-blue-400 font-bold resize-none" /></div>
+                  <div><label className="text-[10px] text-slate-500 uppercase font-black">Min Vol 24h</label>
+                       <TierInput maxLength={12} value={filter.minVolume} onChange={(v) => setFilter({ ...filter, minVolume: v })} /></div>
+                </div>
+              </section>
+              <h2 className="text-[10px] font-black uppercase text-blue-500 italic tracking-widest">Tier Overrides</h2>
+              <section className="space-y-4">
+                {overrides.map((rule, idx) => (
+                  <div key={idx} className="bg-white/5 p-3 border border-white/5 rounded-sm relative group space-y-2">
+                    <div className="grid grid-cols-2 gap-4">
+                      <div><label className="text-[10px] text-slate-500 uppercase font-black">Name</label>
+                           <input type="text" value={rule.name} onChange={(e) => updateOverride(idx, 'name', e.target.value)} className="w-full bg-black/50 border border-white/10 rounded-sm p-1 text-xs text-blue-400 font-bold" /></div>
+                      <div><label className="text-[10px] text-slate-500 uppercase font-black">Symbols</label>
+                           <input type="text" value={(rule.symbols || []).join(',')} onChange={(e) => updateOverride(idx, 'symbols', splitList(e.target.value))} placeholder="BTC_USDT,ETH_USDT" className="w-full bg-black/50 border border-white/10 rounded-sm p-1 text-xs text-blue-400 font-bold" /></div>
+                    </div>
+                    <div className="grid grid-cols-[2fr_1fr_1fr] gap-4">
+                      <div><label className="text-[10px] text-slate-500 uppercase font-black">Patterns</label>
+                           <input type="text" value={(rule.patterns || []).join(',')} onChange={(e) => updateOverride(idx, 'patterns', splitList(e.target.value))} placeholder="*_USDC" className="w-full bg-black/50 border border-white/10 rounded-sm p-1 text-xs text-blue-400 font-bold" /></div>
+                      <div><label className="text-[10px] text-slate-500 uppercase font-black">Min Vol</label>
+                           <TierInput maxLength={12} value={rule.minVolume} onChange={(v) => updateOverride(idx, 'minVolume', v)} /></div>
+                      <div><label className="text-[10px] text-slate-500 uppercase font-black">Max Vol</label>
+                           <TierInput maxLength={12} value={rule.maxVolume} onChange={(v) => updateOverride(idx, 'maxVolume', v)} /></div>
+                    </div>
+                    {(rule.tiers || []).map((tier, tierIdx) => (
+                      <div key={tierIdx} className="grid grid-cols-[1fr_1fr_1fr_20px] gap-4 items-end">
+                        <div><label className="text-[10px] text-slate-500 uppercase font-black">Level (%)</label>
+                             <TierInput value={tier.level} onChange={(v) => updateOverrideTier(idx, tierIdx, 'level', v)} /></div>
+                        <div><label className="text-[10px] text-slate-500 uppercase font-black">Window (m)</label>
+                             <TierInput value={tier.window} onChange={(v) => updateOverrideTier(idx, tierIdx, 'window', v)} /></div>
+                        <div><label className="text-[10px] text-slate-500 uppercase font-black">Direction</label>
+                             <select value={tier.direction || 'BOTH'} onChange={(e) => updateOverrideTier(idx, tierIdx, 'direction', e.target.value)}
+                               className="w-full bg-black/50 border border-white/10 rounded-sm p-1 text-xs text-blue-400 font-bold">
+                               <option value="BOTH">Both</option>
+                               <option value="UP">Pump</option>
+                               <option value="DOWN">Dump</option>
+                             </select></div>
+                        <button onClick={() => updateOverride(idx, 'tiers', rule.tiers.filter((_, i) => i !== tierIdx))} className="h-7 text-slate-600 hover:text-red-500"><Trash2 size={10}/></button>
+                      </div>
+                    ))}
+                    <button onClick={() => updateOverride(idx, 'tiers', [...rule.tiers, { level: 5, window: 5, isForcedPin: false }])} className="w-full py-1 border border-dashed border-white/10 text-slate-500 text-[9px] uppercase font-black hover:bg-white/5 transition-all tracking-widest">+ Tier</button>
+                    <button onClick={() => setOverrides(overrides.filter((_, i) => i !== idx))} className="absolute -right-2 -top-2 bg-red-900/80 p-1 rounded-full text-white opacity-0 group-hover:opacity-100 transition-all"><Trash2 size={10}/></button>
+                  </div>
+                ))}
+                <button onClick={() => setOverrides([...overrides, { name: '', symbols: [], patterns: [], minVolume: 0, maxVolume: 0, tiers: [{ level: 5, window: 5, isForcedPin: false }] }])} className="w-full py-2 border border-dashed border-white/10 text-slate-500 text-[9px] uppercase font-black hover:bg-white/5 transition-all tracking-widest">+ New Override</button>
+              </section>
+              <h2 className="text-[10px] font-black uppercase text-blue-500 italic tracking-widest">Paper Trading</h2>
+              <section className="grid grid-cols-4 gap-2">
+                <div><label className="text-[10px] text-slate-500 uppercase font-black">Size $</label>
+                     <TierInput value={paperConfig.positionSize} onChange={(v) => setPaperConfig({ ...paperConfig, positionSize: v })} /></div>
+                <div><label className="text-[10px] text-slate-500 uppercase font-black">Fee %</label>
+                     <TierInput value={paperConfig.feeRate} onChange={(v) => setPaperConfig({ ...paperConfig, feeRate: v })} /></div>
+                <div><label className="text-[10px] text-slate-500 uppercase font-black">Slip %</label>
+                     <TierInput value={paperConfig.slippage} onChange={(v) => setPaperConfig({ ...paperConfig, slippage: v })} /></div>
+                <div><label className="text-[10px] text-slate-500 uppercase font-black">Stop %</label>
+                     <TierInput value={paperConfig.stopLoss} onChange={(v) => setPaperConfig({ ...paperConfig, stopLoss: v })} /></div>
+              </section>
+              <h2 className="text-[10px] font-black uppercase text-blue-500 italic tracking-widest">Webhooks</h2>
+              <section className="space-y-4 overflow-y-auto custom-scrollbar">
+                {webhooks.map((hook, idx) => (
+                  <div key={idx} className="bg-white/5 p-3 border border-white/5 rounded-sm relative group space-y-2">
+                    <div><label className="text-[10px] text-slate-500 uppercase font-black">URL</label>
+                         <input type="text" value={hook.url} onChange={(e) => updateWebhook(idx, 'url', e.target.value)} className="w-full bg-black/50 border border-white/10 rounded-sm p-1 text-xs text-blue-400 font-bold" /></div>
+                    <div><label className="text-[10px] text-slate-500 uppercase font-black">HMAC Secret</label>
+                         <input type="password" value={hook.secret} onChange={(e) => updateWebhook(idx, 'secret', e.target.value)} className="w-full bg-black/50 border border-white/10 rounded-sm p-1 text-xs text-blue-400 font-bold" /></div>
+                    <div><label className="text-[10px] text-slate-500 uppercase font-black">Template (empty = JSON)</label>
+                         <textarea rows={2} value={hook.template} onChange={(e) => updateWebhook(idx, 'template', e.target.value)} placeholder={'{"content": "{{.symbol}} {{.direction}} {{.level}}% {{.status}}"}'} className="w-full bg-black/50 border border-white/10 rounded-sm p-1 text-xs text-blue-400 font-bold resize-none" /></div>
+                    <button onClick={() => setWebhooks(webhooks.filter((_, i) => i !== idx))} className="absolute -right-2 -top-2 bg-red-900/80 p-1 rounded-full text-white opacity-0 group-hover:opacity-100 transition-all"><Trash2 size={10}/></button>
+                  </div>
+                ))}
+                <button onClick={() => setWebhooks([...webhooks, { url: '', secret: '', template: '', statuses: [] }])} className="w-full py-2 border border-dashed border-white/10 text-slate-500 text-[9px] uppercase font-black hover:bg-white/5 transition-all tracking-widest">+ New Webhook</button>
+              </section>
+              {configErrors.length > 0 && (
+                <section className="bg-red-900/20 border border-red-500/30 p-2 rounded-sm space-y-1 shrink-0">
+                  {configErrors.map((e, i) => (
+                    <div key={i} className="text-[9px] font-bold text-red-400"><AlertTriangle size={9} className="inline mr-1" />{e.field}: {e.message}</div>
+                  ))}
+                </section>
+              )}
               <button className="w-full bg-blue-600 py-3 text-[10px] font-black uppercase mt-auto tracking-widest" onClick={saveStrategy}>Save & Apply</button>
             </motion.aside>
           )}
@@ -267,6 +602,9 @@ const App = () => {
 
       <footer className="h-6 border-t border-white/5 bg-[#050505] flex items-center px-6 justify-between text-[8px] font-bold text-slate-600 uppercase tracking-widest shrink-0">
         <span>Terminus Alpha v0.1</span>
+        <span className={`flex items-center gap-1 ${paperStats.totalPnl >= 0 ? 'text-green-500' : 'text-red-500'}`}>
+          <Wallet size={8}/> Paper: {paperStats.trades} trades | {paperStats.wins} wins | {paperStats.totalPnl.toFixed(2)} USDT
+        </span>
         <span className="text-green-500 flex items-center gap-1"><Zap size={8}/> Parser Active</span>
       </footer>
     </div>
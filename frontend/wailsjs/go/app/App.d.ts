@@ -2,4 +2,40 @@
 // This file is automatically generated. DO NOT EDIT
 import {models} from '../models';
 
-export function UpdateConfig(arg1:models.EngineConfig):Promise<void>;
+export function ActiveProfile():Promise<string>;
+
+export function DeleteProfile(arg1:string):Promise<void>;
+
+export function DismissSplash(arg1:string):Promise<void>;
+
+export function EngineState():Promise<string>;
+
+export function GetConfig():Promise<models.EngineConfig>;
+
+export function GetFeedHealth():Promise<models.FeedHealth>;
+
+export function GetPaperStats():Promise<models.PaperStats>;
+
+export function GetProfileHistory(arg1:string):Promise<Array<models.ConfigRevision>>;
+
+export function IsRecording():Promise<boolean>;
+
+export function ListProfiles():Promise<Array<models.ConfigProfile>>;
+
+export function LoadProfile(arg1:string):Promise<models.EngineConfig>;
+
+export function PauseEngine():Promise<void>;
+
+export function ResumeEngine():Promise<void>;
+
+export function SaveProfile(arg1:string,arg2:models.EngineConfig):Promise<Array<models.ConfigError>>;
+
+export function StartEngine():Promise<void>;
+
+export function StartRecording(arg1:string):Promise<void>;
+
+export function StopEngine():Promise<void>;
+
+export function StopRecording():Promise<void>;
+
+export function UpdateConfig(arg1:models.EngineConfig):Promise<Array<models.ConfigError>>;
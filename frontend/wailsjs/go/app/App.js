@@ -2,6 +2,78 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function ActiveProfile() {
+  return window['go']['app']['App']['ActiveProfile']();
+}
+
+export function DeleteProfile(arg1) {
+  return window['go']['app']['App']['DeleteProfile'](arg1);
+}
+
+export function DismissSplash(arg1) {
+  return window['go']['app']['App']['DismissSplash'](arg1);
+}
+
+export function EngineState() {
+  return window['go']['app']['App']['EngineState']();
+}
+
+export function GetConfig() {
+  return window['go']['app']['App']['GetConfig']();
+}
+
+export function GetFeedHealth() {
+  return window['go']['app']['App']['GetFeedHealth']();
+}
+
+export function GetPaperStats() {
+  return window['go']['app']['App']['GetPaperStats']();
+}
+
+export function GetProfileHistory(arg1) {
+  return window['go']['app']['App']['GetProfileHistory'](arg1);
+}
+
+export function IsRecording() {
+  return window['go']['app']['App']['IsRecording']();
+}
+
+export function ListProfiles() {
+  return window['go']['app']['App']['ListProfiles']();
+}
+
+export function LoadProfile(arg1) {
+  return window['go']['app']['App']['LoadProfile'](arg1);
+}
+
+export function PauseEngine() {
+  return window['go']['app']['App']['PauseEngine']();
+}
+
+export function ResumeEngine() {
+  return window['go']['app']['App']['ResumeEngine']();
+}
+
+export function SaveProfile(arg1,arg2) {
+  return window['go']['app']['App']['SaveProfile'](arg1,arg2);
+}
+
+export function StartEngine() {
+  return window['go']['app']['App']['StartEngine']();
+}
+
+export function StartRecording(arg1) {
+  return window['go']['app']['App']['StartRecording'](arg1);
+}
+
+export function StopEngine() {
+  return window['go']['app']['App']['StopEngine']();
+}
+
+export function StopRecording() {
+  return window['go']['app']['App']['StopRecording']();
+}
+
 export function UpdateConfig(arg1) {
   return window['go']['app']['App']['UpdateConfig'](arg1);
 }
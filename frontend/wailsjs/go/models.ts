@@ -4,6 +4,11 @@ export namespace models {
 	    level: number;
 	    window: number;
 	    isForcedPin: boolean;
+	    paperTrade: boolean;
+	    lookback: number;
+	    direction: string;
+	    toleranceMode: string;
+	    tolerance: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new SplashTier(source);
@@ -14,10 +19,153 @@ export namespace models {
 	        this.level = source["level"];
 	        this.window = source["window"];
 	        this.isForcedPin = source["isForcedPin"];
+	        this.paperTrade = source["paperTrade"];
+	        this.lookback = source["lookback"];
+	        this.direction = source["direction"];
+	        this.toleranceMode = source["toleranceMode"];
+	        this.tolerance = source["tolerance"];
+	    }
+	}
+	export class PaperConfig {
+	    positionSize: number;
+	    feeRate: number;
+	    slippage: number;
+	    stopLoss: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PaperConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.positionSize = source["positionSize"];
+	        this.feeRate = source["feeRate"];
+	        this.slippage = source["slippage"];
+	        this.stopLoss = source["stopLoss"];
+	    }
+	}
+	export class WebhookConfig {
+	    url: string;
+	    secret: string;
+	    template: string;
+	    statuses: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new WebhookConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.secret = source["secret"];
+	        this.template = source["template"];
+	        this.statuses = source["statuses"];
+	    }
+	}
+	export class TierOverride {
+	    name: string;
+	    symbols: string[];
+	    patterns: string[];
+	    minVolume: number;
+	    maxVolume: number;
+	    tiers: SplashTier[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TierOverride(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.symbols = source["symbols"];
+	        this.patterns = source["patterns"];
+	        this.minVolume = source["minVolume"];
+	        this.maxVolume = source["maxVolume"];
+	        this.tiers = this.convertValues(source["tiers"], SplashTier);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SymbolFilter {
+	    include: string[];
+	    exclude: string[];
+	    includeRegex: string[];
+	    excludeRegex: string[];
+	    minVolume: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SymbolFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.include = source["include"];
+	        this.exclude = source["exclude"];
+	        this.includeRegex = source["includeRegex"];
+	        this.excludeRegex = source["excludeRegex"];
+	        this.minVolume = source["minVolume"];
+	    }
+	}
+	export class AdaptiveConfig {
+	    enabled: boolean;
+	    baseline: number;
+	    minScale: number;
+	    maxScale: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AdaptiveConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.baseline = source["baseline"];
+	        this.minScale = source["minScale"];
+	        this.maxScale = source["maxScale"];
+	    }
+	}
+	export class SanityConfig {
+	    maxJump: number;
+	    medianWindow: number;
+	    confirmUpdates: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SanityConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.maxJump = source["maxJump"];
+	        this.medianWindow = source["medianWindow"];
+	        this.confirmUpdates = source["confirmUpdates"];
 	    }
 	}
 	export class EngineConfig {
+	    window: number;
 	    tiers: SplashTier[];
+	    overrides: TierOverride[];
+	    filter: SymbolFilter;
+	    adaptive: AdaptiveConfig;
+	    sanity: SanityConfig;
+	    webhooks: WebhookConfig[];
+	    paper: PaperConfig;
+	    staleAfter: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new EngineConfig(source);
@@ -25,7 +173,15 @@ export namespace models {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.window = source["window"];
 	        this.tiers = this.convertValues(source["tiers"], SplashTier);
+	        this.overrides = this.convertValues(source["overrides"], TierOverride);
+	        this.filter = this.convertValues(source["filter"], SymbolFilter);
+	        this.adaptive = this.convertValues(source["adaptive"], AdaptiveConfig);
+	        this.sanity = this.convertValues(source["sanity"], SanityConfig);
+	        this.webhooks = this.convertValues(source["webhooks"], WebhookConfig);
+	        this.paper = this.convertValues(source["paper"], PaperConfig);
+	        this.staleAfter = source["staleAfter"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -47,5 +203,160 @@ export namespace models {
 		}
 	}
 
+	export class ConfigError {
+	    field: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.message = source["message"];
+	    }
+	}
+	export class ConfigProfile {
+	    id: number;
+	    name: string;
+	    config: EngineConfig;
+	    active: boolean;
+	    // Go type: time
+	    updatedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.config = this.convertValues(source["config"], EngineConfig);
+	        this.active = source["active"];
+	        this.updatedAt = this.convertValues(source["updatedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ConfigRevision {
+	    id: number;
+	    config: EngineConfig;
+	    // Go type: time
+	    savedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigRevision(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.config = this.convertValues(source["config"], EngineConfig);
+	        this.savedAt = this.convertValues(source["savedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FeedHealth {
+	    status: string;
+	    consecutiveFailures: number;
+	    requests: number;
+	    failures: number;
+	    rateLimited: number;
+	    latencyMs: number;
+	    lastError: string;
+	    // Go type: time
+	    lastSuccess: any;
+	    // Go type: time
+	    retryAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new FeedHealth(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.consecutiveFailures = source["consecutiveFailures"];
+	        this.requests = source["requests"];
+	        this.failures = source["failures"];
+	        this.rateLimited = source["rateLimited"];
+	        this.latencyMs = source["latencyMs"];
+	        this.lastError = source["lastError"];
+	        this.lastSuccess = this.convertValues(source["lastSuccess"], null);
+	        this.retryAt = this.convertValues(source["retryAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PaperStats {
+	    trades: number;
+	    wins: number;
+	    totalPnl: number;
+	    avgPnl: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PaperStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.trades = source["trades"];
+	        this.wins = source["wins"];
+	        this.totalPnl = source["totalPnl"];
+	        this.avgPnl = source["avgPnl"];
+	    }
+	}
+
 }
 
@@ -0,0 +1,84 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func received(c <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-c:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestFakeTickerFiresOnAdvance(t *testing.T) {
+	f := NewFake(start)
+	tk := f.NewTicker(time.Second)
+	defer tk.Stop()
+
+	f.Advance(999 * time.Millisecond)
+	if _, ok := received(tk.C()); ok {
+		t.Fatal("ticker fired before its period elapsed")
+	}
+
+	f.Advance(time.Millisecond)
+	got, ok := received(tk.C())
+	if !ok {
+		t.Fatal("ticker did not fire after one period")
+	}
+	if want := start.Add(time.Second); !got.Equal(want) {
+		t.Fatalf("tick time = %s, want %s", got, want)
+	}
+}
+
+func TestFakeTickerDropsMissedTicks(t *testing.T) {
+	f := NewFake(start)
+	tk := f.NewTicker(time.Second)
+	defer tk.Stop()
+
+	// как и time.Ticker: за один перевод на 3.5 периода в канале остается один такт
+	f.Advance(3500 * time.Millisecond)
+	if _, ok := received(tk.C()); !ok {
+		t.Fatal("ticker did not fire")
+	}
+	if _, ok := received(tk.C()); ok {
+		t.Fatal("missed ticks were queued")
+	}
+
+	// следующий такт — на границе периода, а не через период от перевода
+	f.Advance(500 * time.Millisecond)
+	got, ok := received(tk.C())
+	if !ok {
+		t.Fatal("ticker did not fire on the next period boundary")
+	}
+	if want := start.Add(4 * time.Second); !got.Equal(want) {
+		t.Fatalf("tick time = %s, want %s", got, want)
+	}
+}
+
+func TestFakeTickerStop(t *testing.T) {
+	f := NewFake(start)
+	tk := f.NewTicker(time.Second)
+	tk.Stop()
+
+	f.Advance(2 * time.Second)
+	if _, ok := received(tk.C()); ok {
+		t.Fatal("stopped ticker fired")
+	}
+}
+
+func TestFakeSetIgnoresGoingBack(t *testing.T) {
+	f := NewFake(start)
+	f.Set(start.Add(-time.Hour))
+	if !f.Now().Equal(start) {
+		t.Fatalf("Now = %s after setting the clock back, want %s", f.Now(), start)
+	}
+	if got := f.Since(start.Add(-time.Minute)); got != time.Minute {
+		t.Fatalf("Since = %s, want 1m", got)
+	}
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// SymbolFilter отсекает символы до детекции. Include/Exclude — точные имена контрактов,
+// IncludeRegex/ExcludeRegex — регулярные выражения. Исключения сильнее включений;
+// если включения не заданы, проходят все символы. MinVolume — порог Volume24, 0 — без порога.
+type SymbolFilter struct {
+	Include      []string `json:"include"`
+	Exclude      []string `json:"exclude"`
+	IncludeRegex []string `json:"includeRegex"`
+	ExcludeRegex []string `json:"excludeRegex"`
+	MinVolume    float64  `json:"minVolume"`
+}
+
+// скомпилированные выражения фильтра; некорректные хранятся как nil и ничего не совпадают
+var filterRegexps sync.Map
+
+func (f SymbolFilter) Allows(symbol string, volume float64) bool {
+	if f.MinVolume > 0 && volume < f.MinVolume {
+		return false
+	}
+	if containsSymbol(f.Exclude, symbol) || matchesAny(f.ExcludeRegex, symbol) {
+		return false
+	}
+	if len(f.Include) == 0 && len(f.IncludeRegex) == 0 {
+		return true
+	}
+	return containsSymbol(f.Include, symbol) || matchesAny(f.IncludeRegex, symbol)
+}
+
+func containsSymbol(list []string, symbol string) bool {
+	for _, s := range list {
+		if strings.EqualFold(strings.TrimSpace(s), symbol) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(patterns []string, symbol string) bool {
+	for _, p := range patterns {
+		if p == "" {
+			continue
+		}
+		if re := compileFilterRegex(p); re != nil && re.MatchString(symbol) {
+			return true
+		}
+	}
+	return false
+}
+
+func compileFilterRegex(pattern string) *regexp.Regexp {
+	if cached, ok := filterRegexps.Load(pattern); ok {
+		return cached.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = nil
+	}
+	filterRegexps.Store(pattern, re)
+	return re
+}
@@ -2,7 +2,12 @@ package models
 
 import (
 	"context"
-	"sync"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -12,27 +17,282 @@ const (
 	ReturnTolerance = 0.005
 )
 
+// Режимы допуска возврата для тира.
+const (
+	ToleranceAuto     = "auto"     // ReturnTolerance + 10% от уровня тира
+	TolerancePercent  = "percent"  // абсолютный процент от референса
+	ToleranceFraction = "fraction" // доля от фактического размера сплеша
+	ToleranceATR      = "atr"      // множитель ATR по минутным барам
+)
+
+// Статусы сплеша в splash_records и событиях UI.
+const (
+	StatusActive    = "ACTIVE"
+	StatusReturned  = "RETURNED"
+	StatusTimeout   = "TIMEOUT"
+	StatusDismissed = "DISMISSED" // снят вручную, обычно закрепленный тир
+	StatusCancelled = "CANCELLED" // движок остановлен, пока сплеш был активен
+)
+
+// Направления сплеша. Пустое направление у тира означает оба.
+const (
+	DirectionUp   = "UP"
+	DirectionDown = "DOWN"
+	DirectionBoth = "BOTH"
+)
+
+const (
+	AtrBarPeriod = time.Minute
+	AtrPeriod    = 14
+	AtrMinBars   = 3
+)
+
+// Реализованная волатильность считается по тем же минутным барам, что и ATR.
+const (
+	VolPeriod  = 60
+	VolMinBars = 10
+)
+
+// DefaultStaleAfter — сколько символ может не обновляться, прежде чем его история цен считается разорванной.
+const DefaultStaleAfter = 10 * time.Second
+
+// Сколько последних принтов символа хранится для проверки скачков цены: по умолчанию и не больше.
+const (
+	DefaultMedianWindow = 3
+	MaxMedianWindow     = 25
+)
+
 var AppCtx context.Context
 
 type SplashTier struct {
 	Level       float64 `json:"level"`
 	Window      int     `json:"window"`
 	IsForcedPin bool    `json:"isForcedPin"`
+	PaperTrade  bool    `json:"paperTrade"`
+	Lookback    int     `json:"lookback"`
+	Direction   string  `json:"direction"`
+
+	ToleranceMode string  `json:"toleranceMode"`
+	Tolerance     float64 `json:"tolerance"`
+}
+
+// Matches сообщает, работает ли тир для сплеша в направлении direction.
+func (t SplashTier) Matches(direction string) bool {
+	return t.Direction == "" || t.Direction == DirectionBoth || t.Direction == direction
+}
+
+// PaperConfig — параметры симуляции сделок. Проценты задаются как есть: 0.02 = 0.02%.
+type PaperConfig struct {
+	PositionSize float64 `json:"positionSize"`
+	FeeRate      float64 `json:"feeRate"`
+	Slippage     float64 `json:"slippage"`
+	StopLoss     float64 `json:"stopLoss"`
+}
+
+type WebhookConfig struct {
+	URL      string   `json:"url"`
+	Secret   string   `json:"secret"`
+	Template string   `json:"template"`
+	Statuses []string `json:"statuses"`
+}
+
+// TierOverride подменяет набор тиров для части символов. Символ подходит, если он есть в Symbols
+// или совпадает с одним из glob-шаблонов Patterns (например "*_USDC"), и его Volume24
+// попадает в [MinVolume, MaxVolume). Нулевая граница объема не проверяется.
+// Если ни символы, ни шаблоны не заданы, правило отбирает символы только по объему.
+// Правило с пустым Tiers отключает детект для подходящих символов.
+type TierOverride struct {
+	Name      string       `json:"name"`
+	Symbols   []string     `json:"symbols"`
+	Patterns  []string     `json:"patterns"`
+	MinVolume float64      `json:"minVolume"`
+	MaxVolume float64      `json:"maxVolume"`
+	Tiers     []SplashTier `json:"tiers"`
+}
+
+func (o TierOverride) Matches(symbol string, volume float64) bool {
+	if len(o.Symbols) == 0 && len(o.Patterns) == 0 && o.MinVolume <= 0 && o.MaxVolume <= 0 {
+		return false
+	}
+	if o.MinVolume > 0 && volume < o.MinVolume {
+		return false
+	}
+	if o.MaxVolume > 0 && volume >= o.MaxVolume {
+		return false
+	}
+	if len(o.Symbols) == 0 && len(o.Patterns) == 0 {
+		return true
+	}
+
+	for _, s := range o.Symbols {
+		if strings.EqualFold(strings.TrimSpace(s), symbol) {
+			return true
+		}
+	}
+	for _, p := range o.Patterns {
+		if ok, _ := path.Match(strings.ToUpper(strings.TrimSpace(p)), strings.ToUpper(symbol)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// AdaptiveConfig — подстройка уровней тиров под волатильность символа. Уровень тира умножается на
+// отношение минутной реализованной волатильности символа к Baseline (в процентах), ограниченное
+// диапазоном [MinScale, MaxScale].
+type AdaptiveConfig struct {
+	Enabled  bool    `json:"enabled"`
+	Baseline float64 `json:"baseline"`
+	MinScale float64 `json:"minScale"`
+	MaxScale float64 `json:"maxScale"`
+}
+
+// SanityConfig — фильтр плохих принтов. MaxJump — наибольший правдоподобный скачок цены (в процентах)
+// от медианы последних MedianWindow принтов; больший скачок принимается, только когда его подтвердит
+// большинство из MedianWindow принтов. ConfirmUpdates — сколько снимков подряд сплеш должен держаться,
+// прежде чем сработать; 0 и 1 — сразу.
+type SanityConfig struct {
+	MaxJump        float64 `json:"maxJump"`
+	MedianWindow   int     `json:"medianWindow"`
+	ConfirmUpdates int     `json:"confirmUpdates"`
+}
+
+// MedianSize — размер окна медианы, 0 — DefaultMedianWindow.
+func (s SanityConfig) MedianSize() int {
+	if s.MedianWindow > 0 {
+		return s.MedianWindow
+	}
+	return DefaultMedianWindow
 }
 
 type EngineConfig struct {
-	Tiers []SplashTier `json:"tiers"`
+	Window    int             `json:"window"`
+	Tiers     []SplashTier    `json:"tiers"`
+	Overrides []TierOverride  `json:"overrides"`
+	Filter    SymbolFilter    `json:"filter"`
+	Adaptive  AdaptiveConfig  `json:"adaptive"`
+	Sanity    SanityConfig    `json:"sanity"`
+	Webhooks  []WebhookConfig `json:"webhooks"`
+	Paper     PaperConfig     `json:"paper"`
+
+	// StaleAfter — секунды без обновлений символа, после которых его история сбрасывается; 0 — DefaultStaleAfter.
+	StaleAfter int `json:"staleAfter"`
+
+	// Version — id записи config_versions, под которой применен этот снимок; 0, если версия не сохранена.
+	Version int64 `json:"-"`
+}
+
+func (c EngineConfig) StaleAfterDuration() time.Duration {
+	if c.StaleAfter > 0 {
+		return time.Duration(c.StaleAfter) * time.Second
+	}
+	return DefaultStaleAfter
+}
+
+// Detection — часть конфигурации, от которой зависят сигналы: без вебхуков (их URL и секреты
+// не должны попадать в историю версий), настроек бумажной торговли и версии.
+func (c EngineConfig) Detection() EngineConfig {
+	c.Webhooks = nil
+	c.Paper = PaperConfig{}
+	c.Version = 0
+	return c
+}
+
+// Fingerprint — SHA-256 от JSON детекционной части конфигурации. Правка вебхуков или бумажной
+// торговли хеш не меняет, поэтому не плодит версии.
+func (c EngineConfig) Fingerprint() string {
+	encoded, _ := json.Marshal(c.Detection())
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
+
+// TiersFor возвращает тиры для символа: первое подходящее правило из Overrides или общие тиры.
+func (c EngineConfig) TiersFor(symbol string, volume float64) []SplashTier {
+	for _, o := range c.Overrides {
+		if o.Matches(symbol, volume) {
+			return o.Tiers
+		}
+	}
+	return c.Tiers
+}
+
+// TierLookback — окно, за которое измеряется изменение цены для тира. Порядок: окно тира,
+// общее окно конфигурации, затем models.Window.
+func (c EngineConfig) TierLookback(t SplashTier) time.Duration {
+	if t.Lookback > 0 {
+		return time.Duration(t.Lookback) * time.Minute
+	}
+	if c.Window > 0 {
+		return time.Duration(c.Window) * time.Minute
+	}
+	return Window
+}
+
+// Lookbacks возвращает все различные окна, используемые общими тирами и правилами Overrides.
+func (c EngineConfig) Lookbacks() []time.Duration {
+	tiers := c.Tiers
+	for _, o := range c.Overrides {
+		tiers = append(tiers[:len(tiers):len(tiers)], o.Tiers...)
+	}
+	return c.TierLookbacks(tiers)
+}
+
+// TierLookbacks возвращает различные окна набора тиров в порядке их появления.
+func (c EngineConfig) TierLookbacks(tiers []SplashTier) []time.Duration {
+	var out []time.Duration
+	seen := make(map[time.Duration]bool)
+	for _, t := range tiers {
+		lb := c.TierLookback(t)
+		if !seen[lb] {
+			seen[lb] = true
+			out = append(out, lb)
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, c.TierLookback(SplashTier{}))
+	}
+	return out
+}
+
+// MaxLookback — самое длинное окно отсчета; его должна покрывать история цен.
+func (c EngineConfig) MaxLookback() time.Duration {
+	var longest time.Duration
+	for _, lb := range c.Lookbacks() {
+		longest = max(longest, lb)
+	}
+	return longest
+}
+
+// ConfigProfile — именованная сохраненная конфигурация движка. Активный профиль загружается при запуске.
+type ConfigProfile struct {
+	ID        int64        `json:"id"`
+	Name      string       `json:"name"`
+	Config    EngineConfig `json:"config"`
+	Active    bool         `json:"active"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+// ConfigRevision — одна из прошлых версий профиля.
+type ConfigRevision struct {
+	ID      int64        `json:"id"`
+	Config  EngineConfig `json:"config"`
+	SavedAt time.Time    `json:"savedAt"`
 }
 
 type Responce struct {
 	Code    int          `json:"code"`
 	Msg     string       `json:"msg"`
 	Data    []SplashData `json:"data"`
-	Success bool         `json: "success"`
+	Success bool         `json:"success"`
 }
+
+// PriceRecord — диапазон цен символа за один шаг PriceRing. Time — начало шага в unix ms.
 type PriceRecord struct {
-	Price float64
-	Time  int64
+	LastLow  float64
+	LastHigh float64
+	FairLow  float64
+	FairHigh float64
+	Time     int64
 }
 
 type SplashData struct {
@@ -55,34 +315,150 @@ type SplashRecord struct {
 	TriggerTime      time.Time
 	Volume24h        float64
 	Returned         bool
+	Status           string
 	ReturnTime       time.Duration
 	MaxDeviation     float64
+	ToleranceMode    string
+	Tolerance        float64
+	LevelScale       float64
+	ForcedPin        bool
+	ConfigVersion    int64
+	DataGap          bool // сплеш рядом с разрывом данных: история до срабатывания или слежение неполные
 	LongProbability  float64
 	ShortProbability float64
 }
 
+type PaperTrade struct {
+	ID         int64     `json:"id"`
+	RecordID   int64     `json:"recordId"`
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"`
+	Level      int       `json:"level"`
+	Size       float64   `json:"size"`
+	EntryPrice float64   `json:"entryPrice"`
+	ExitPrice  float64   `json:"exitPrice"`
+	StopPrice  float64   `json:"stopPrice"`
+	EntryTime  time.Time `json:"entryTime"`
+	ExitTime   time.Time `json:"exitTime"`
+	ExitReason string    `json:"exitReason"`
+	Fees       float64   `json:"fees"`
+	PnL        float64   `json:"pnl"`
+	PnLPercent float64   `json:"pnlPercent"`
+}
+
+type PaperStats struct {
+	Trades   int     `json:"trades"`
+	Wins     int     `json:"wins"`
+	TotalPnL float64 `json:"totalPnl"`
+	AvgPnL   float64 `json:"avgPnl"`
+}
+
+// Состояния ленты тикеров.
+const (
+	FeedConnecting  = "CONNECTING"
+	FeedOK          = "OK"
+	FeedDegraded    = "DEGRADED"     // запросы падают, идут повторы с backoff
+	FeedRateLimited = "RATE_LIMITED" // биржа ответила 429, ждем Retry-After
+	FeedDown        = "DOWN"         // цепь разомкнута: запросы не отправляются до конца паузы
+)
+
+// FeedHealth — состояние опроса биржи для UI.
+type FeedHealth struct {
+	Status              string    `json:"status"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	Requests            int64     `json:"requests"`
+	Failures            int64     `json:"failures"`
+	RateLimited         int64     `json:"rateLimited"`
+	LatencyMs           int64     `json:"latencyMs"`
+	LastError           string    `json:"lastError"`
+	LastSuccess         time.Time `json:"lastSuccess"`
+	RetryAt             time.Time `json:"retryAt"`
+}
+
+// RefPoint — точка отсчета изменения: скользящий экстремум цены и время, когда он был достигнут.
+type RefPoint struct {
+	Data  SplashData
+	Since time.Time
+}
+
 type TickerState struct {
-	WindowStartRef     SplashData
+	History            *PriceRing
+	HistoryFrom        time.Time
 	LatestTickerData   SplashData
 	LastTriggeredLevel float64
 	SplashTrigger      bool
 	TriggerTime        time.Time
-	LastRefUpdate      time.Time
 	CurrentTimeWindow  int
 	SplashDirection    string
 	SplashRecordID     int64
+	ReturnTolerance    float64
+	LevelScale         float64
+	ForcedPin          bool // сплеш закрепленного тира: без прогрессии и таймаута
+	Dismissed          bool // пользователь снял сплеш, слежение закроет его на следующем шаге
+	LastUpdate         time.Time
+	GapAt              time.Time // последний разрыв ленты по символу
+
+	RecentPrints  []SplashData // последние принты, включая отброшенные, для медианы скачков
+	RejectedTicks int          // подряд отброшенных фильтрами тикеров
+	PendingDir    string       // направление сплеша, ожидающего подтверждения
+	PendingHits   int          // снимков подряд, на которых он держится
+
+	Atr       float64
+	AtrBars   int
+	BarStart  time.Time
+	BarHigh   float64
+	BarLow    float64
+	BarClose  float64
+	PrevClose float64
+
+	RealizedVar float64
+	VolBars     int
+}
+
+// DefaultConfig — встроенная конфигурация, пока не загружен сохраненный профиль.
+func DefaultConfig() EngineConfig {
+	return EngineConfig{
+		Window:     5,
+		StaleAfter: int(DefaultStaleAfter / time.Second),
+		Tiers: []SplashTier{
+			{Level: 3, Window: 10, IsForcedPin: false},
+			{Level: 5, Window: 15, IsForcedPin: false},
+		},
+		Filter: SymbolFilter{
+			ExcludeRegex: []string{`^(USDC|FDUSD|TUSD|DAI)_USDT$`},
+		},
+		Adaptive: AdaptiveConfig{
+			Baseline: 0.3,
+			MinScale: 0.5,
+			MaxScale: 3,
+		},
+		Sanity: SanityConfig{
+			MaxJump:      30,
+			MedianWindow: DefaultMedianWindow,
+		},
+		Paper: PaperConfig{
+			PositionSize: 100,
+			FeeRate:      0.02,
+			Slippage:     0.05,
+			StopLoss:     3,
+		},
+	}
+}
+
+var currentConfig atomic.Pointer[EngineConfig]
 
-	UpdateChan chan SplashData
+func init() {
+	cfg := DefaultConfig()
+	currentConfig.Store(&cfg)
 }
 
-type SharedState struct {
-	TickerStates map[string]TickerState
-	Mu           sync.Mutex
+// Config возвращает текущий снимок конфигурации. Снимок неизменяем: вызывающий код не должен
+// править его срезы, а за весь проход детекции берет один снимок.
+func Config() *EngineConfig {
+	return currentConfig.Load()
 }
 
-var CurrentConfig = EngineConfig{
-	Tiers: []SplashTier{
-		{Level: 3, Window: 10, IsForcedPin: false},
-		{Level: 5, Window: 15, IsForcedPin: false},
-	},
+// SetConfig атомарно подменяет конфигурацию. Проверка — на стороне вызывающего (client.ValidateConfig).
+func SetConfig(cfg EngineConfig) {
+	currentConfig.Store(&cfg)
 }
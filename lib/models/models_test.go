@@ -0,0 +1,79 @@
+package models
+
+import "testing"
+
+func TestFingerprintCoversOnlyDetection(t *testing.T) {
+	base := DefaultConfig()
+	hash := base.Fingerprint()
+
+	same := []func(c *EngineConfig){
+		func(c *EngineConfig) {
+			c.Webhooks = []WebhookConfig{{URL: "https://hooks.example/1", Secret: "s3cret"}}
+		},
+		func(c *EngineConfig) { c.Paper.PositionSize = 500 },
+		func(c *EngineConfig) { c.Version = 42 },
+	}
+	for i, edit := range same {
+		c := DefaultConfig()
+		edit(&c)
+		if c.Fingerprint() != hash {
+			t.Errorf("edit %d changed the fingerprint, want it ignored", i)
+		}
+	}
+
+	changed := []func(c *EngineConfig){
+		func(c *EngineConfig) { c.Tiers = append(c.Tiers, SplashTier{Level: 9, Window: 5}) },
+		func(c *EngineConfig) { c.Overrides = []TierOverride{{Symbols: []string{"BTC_USDT"}}} },
+		func(c *EngineConfig) { c.Filter.MinVolume = 1e6 },
+		func(c *EngineConfig) { c.Tiers[0].ToleranceMode = TolerancePercent },
+		func(c *EngineConfig) { c.Sanity.ConfirmUpdates = 3 },
+		func(c *EngineConfig) { c.StaleAfter = 30 },
+	}
+	for i, edit := range changed {
+		c := DefaultConfig()
+		c.Tiers = append([]SplashTier(nil), c.Tiers...)
+		edit(&c)
+		if c.Fingerprint() == hash {
+			t.Errorf("edit %d kept the fingerprint, want a new version", i)
+		}
+	}
+}
+
+func TestDetectionDropsSecrets(t *testing.T) {
+	c := DefaultConfig()
+	c.Webhooks = []WebhookConfig{{URL: "https://hooks.example/token", Secret: "s3cret"}}
+	d := c.Detection()
+	if d.Webhooks != nil || d.Paper != (PaperConfig{}) {
+		t.Fatalf("Detection() = %+v, want webhooks and paper settings removed", d)
+	}
+	if len(c.Webhooks) != 1 {
+		t.Fatal("Detection() modified the original config")
+	}
+}
+
+func TestLookbackLimit(t *testing.T) {
+	cases := []struct {
+		edit  func(c *EngineConfig)
+		field string
+	}{
+		{func(c *EngineConfig) { c.Window = 61 }, "window"},
+		{func(c *EngineConfig) { c.Tiers[0].Lookback = 24 * 60 }, "tiers[0].lookback"},
+	}
+	for _, tc := range cases {
+		c := DefaultConfig()
+		c.Tiers = append([]SplashTier(nil), c.Tiers...)
+		tc.edit(&c)
+		errs := c.Validate()
+		if len(errs) != 1 || errs[0].Field != tc.field {
+			t.Errorf("Validate() = %v, want one error on %s", errs, tc.field)
+		}
+	}
+
+	c := DefaultConfig()
+	c.Window = 60
+	c.Tiers = append([]SplashTier(nil), c.Tiers...)
+	c.Tiers[0].Lookback = 60
+	if errs := c.Validate(); len(errs) != 0 {
+		t.Fatalf("Validate() = %v, want an hour-long lookback accepted", errs)
+	}
+}
@@ -0,0 +1,115 @@
+package models
+
+import "time"
+
+// HistoryResolution — шаг кольцевого буфера цен. Все тики внутри шага сворачиваются в один PriceRecord.
+const HistoryResolution = time.Second
+
+// PriceRing — кольцевой буфер недавних цен символа, индексированный по времени.
+// Хранит минимумы и максимумы LastPrice/FairPrice по интервалам HistoryResolution.
+type PriceRing struct {
+	buf  []PriceRecord
+	head int
+	size int
+}
+
+func NewPriceRing(window time.Duration) *PriceRing {
+	r := &PriceRing{}
+	r.EnsureWindow(window)
+	return r
+}
+
+// EnsureWindow увеличивает буфер так, чтобы он покрывал окно window. Уже накопленные данные сохраняются.
+func (r *PriceRing) EnsureWindow(window time.Duration) {
+	capacity := int(window/HistoryResolution) + 2
+	if capacity <= len(r.buf) {
+		return
+	}
+
+	buf := make([]PriceRecord, capacity)
+	for i := 0; i < r.size; i++ {
+		buf[i] = r.at(i)
+	}
+	r.buf = buf
+	r.head = r.size % capacity
+}
+
+// Push добавляет тик в текущий интервал или открывает новый, вытесняя самый старый.
+func (r *PriceRing) Push(t SplashData, at time.Time) {
+	if len(r.buf) == 0 {
+		r.EnsureWindow(Window)
+	}
+
+	bucket := at.Truncate(HistoryResolution).UnixMilli()
+	if r.size > 0 {
+		last := &r.buf[(r.head-1+len(r.buf))%len(r.buf)]
+		if last.Time == bucket {
+			last.LastLow = min(last.LastLow, t.LastPrice)
+			last.LastHigh = max(last.LastHigh, t.LastPrice)
+			last.FairLow = min(last.FairLow, t.FairPrice)
+			last.FairHigh = max(last.FairHigh, t.FairPrice)
+			return
+		}
+	}
+
+	r.buf[r.head] = PriceRecord{
+		LastLow:  t.LastPrice,
+		LastHigh: t.LastPrice,
+		FairLow:  t.FairPrice,
+		FairHigh: t.FairPrice,
+		Time:     bucket,
+	}
+	r.head = (r.head + 1) % len(r.buf)
+	if r.size < len(r.buf) {
+		r.size++
+	}
+}
+
+// Extremes возвращает скользящий минимум и максимум цен с момента from.
+// Время точки — начало интервала, в котором был достигнут экстремум LastPrice.
+func (r *PriceRing) Extremes(from time.Time) (low RefPoint, high RefPoint, ok bool) {
+	cutoff := from.Truncate(HistoryResolution).UnixMilli()
+
+	for i := r.size - 1; i >= 0; i-- {
+		rec := r.at(i)
+		if rec.Time < cutoff {
+			break
+		}
+		at := time.UnixMilli(rec.Time)
+
+		if !ok {
+			low = RefPoint{Data: SplashData{LastPrice: rec.LastLow, FairPrice: rec.FairLow}, Since: at}
+			high = RefPoint{Data: SplashData{LastPrice: rec.LastHigh, FairPrice: rec.FairHigh}, Since: at}
+			ok = true
+			continue
+		}
+
+		if rec.LastLow <= low.Data.LastPrice {
+			low.Data.LastPrice = rec.LastLow
+			low.Since = at
+		}
+		low.Data.FairPrice = min(low.Data.FairPrice, rec.FairLow)
+
+		if rec.LastHigh >= high.Data.LastPrice {
+			high.Data.LastPrice = rec.LastHigh
+			high.Since = at
+		}
+		high.Data.FairPrice = max(high.Data.FairPrice, rec.FairHigh)
+	}
+	return low, high, ok
+}
+
+func (r *PriceRing) Len() int {
+	return r.size
+}
+
+func (r *PriceRing) Reset() {
+	r.head = 0
+	r.size = 0
+}
+
+// at возвращает i-ю запись от самой старой.
+func (r *PriceRing) at(i int) PriceRecord {
+	start := (r.head - r.size + len(r.buf)) % len(r.buf)
+	return r.buf[(start+i)%len(r.buf)]
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// StateShards — число шардов состояния. Степень двойки, чтобы шард выбирался маской.
+// На одном ядре шарды медленнее одной карты под блокировкой (Broadcast обходит все воркеры),
+// выигрыш появляется только при GOMAXPROCS > 1; замеры — BenchmarkStateSharded.
+const StateShards = 64
+
+// shardQueue — размер очереди задач шарда. Цикл опроса ставит не больше пары задач за снимок,
+// остальное — редкие запросы из UI.
+const shardQueue = 16
+
+// StateShard — часть состояния символов, которой владеет одна горутина-воркер.
+// States читаются и меняются только внутри задач шарда, поэтому блокировки не нужны.
+type StateShard struct {
+	Index  int
+	States map[string]TickerState
+
+	UpdateChan chan func(sh *StateShard)
+}
+
+// SharedState — состояние всех символов, разбитое на шарды с собственными воркерами.
+// Символ всегда попадает в один и тот же шард, поэтому все его обновления, детекция
+// и слежение за сплешем выполняются последовательно в одной горутине.
+type SharedState struct {
+	seed   maphash.Seed
+	shards [StateShards]StateShard
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewSharedState создает состояние и запускает воркеры шардов. Остановка — Close.
+func NewSharedState() *SharedState {
+	s := &SharedState{seed: maphash.MakeSeed(), done: make(chan struct{})}
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.Index = i
+		sh.States = make(map[string]TickerState)
+		sh.UpdateChan = make(chan func(sh *StateShard), shardQueue)
+		go sh.run(s.done)
+	}
+	return s
+}
+
+func (sh *StateShard) run(done <-chan struct{}) {
+	for {
+		select {
+		case task := <-sh.UpdateChan:
+			task(sh)
+		case <-done:
+			return
+		}
+	}
+}
+
+// submit ставит задачу в очередь шарда. После Close задача отбрасывается и возвращается false.
+func (s *SharedState) submit(i int, task func(sh *StateShard)) bool {
+	select {
+	case s.shards[i].UpdateChan <- task:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// ShardIndex возвращает номер шарда символа.
+func (s *SharedState) ShardIndex(symbol string) int {
+	return int(maphash.String(s.seed, symbol) & (StateShards - 1))
+}
+
+// Do выполняет fn в воркере шарда символа и ждет завершения. Возвращает false, если состояние
+// уже закрыто и fn не выполнялась. Нельзя вызывать из задачи того же шарда — воркер заблокируется на самом себе.
+func (s *SharedState) Do(symbol string, fn func(sh *StateShard)) bool {
+	finished := make(chan struct{})
+	task := func(sh *StateShard) {
+		defer close(finished)
+		fn(sh)
+	}
+	if !s.submit(s.ShardIndex(symbol), task) {
+		return false
+	}
+	select {
+	case <-finished:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// Broadcast выполняет fn во всех шардах параллельно и ждет, пока закончат все.
+// После Close ничего не выполняет.
+func (s *SharedState) Broadcast(fn func(sh *StateShard)) {
+	var wg sync.WaitGroup
+	for i := range s.shards {
+		wg.Add(1)
+		task := func(sh *StateShard) {
+			defer wg.Done()
+			fn(sh)
+		}
+		if !s.submit(i, task) {
+			wg.Done()
+			break
+		}
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	// задачи, принятые в очередь до Close, могут так и не выполниться
+	select {
+	case <-finished:
+	case <-s.done:
+	}
+}
+
+func (s *SharedState) Get(symbol string) (state TickerState, ok bool) {
+	s.Do(symbol, func(sh *StateShard) {
+		state, ok = sh.States[symbol]
+	})
+	return state, ok
+}
+
+func (s *SharedState) Set(symbol string, state TickerState) {
+	s.Do(symbol, func(sh *StateShard) {
+		sh.States[symbol] = state
+	})
+}
+
+// Close останавливает воркеры. Задачи, поставленные после Close, не выполняются.
+func (s *SharedState) Close() {
+	s.once.Do(func() { close(s.done) })
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+var benchSizes = []int{1000, 5000}
+
+func benchSymbols(n int) []string {
+	symbols := make([]string, n)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("SYM%05d_USDT", i)
+	}
+	return symbols
+}
+
+// BenchmarkStateSharded применяет снимок из n обновлений через воркеры шардов.
+// Одна операция — один снимок. На одном ядре это медленнее одной карты под блокировкой
+// (см. BenchmarkStateSingleMutex): Broadcast платит за передачу задачи в каждый из StateShards воркеров.
+func BenchmarkStateSharded(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprintf("symbols=%d", n), func(b *testing.B) {
+			state := NewSharedState()
+			defer state.Close()
+
+			var batches [StateShards][]string
+			for _, symbol := range benchSymbols(n) {
+				state.Set(symbol, TickerState{})
+				i := state.ShardIndex(symbol)
+				batches[i] = append(batches[i], symbol)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				state.Broadcast(func(sh *StateShard) {
+					for _, symbol := range batches[sh.Index] {
+						s := sh.States[symbol]
+						s.LatestTickerData.LastPrice++
+						sh.States[symbol] = s
+					}
+				})
+			}
+		})
+	}
+}
+
+// BenchmarkStateSingleMutex повторяет прежнюю схему: одна карта состояний, снимок применяется под одной блокировкой.
+func BenchmarkStateSingleMutex(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprintf("symbols=%d", n), func(b *testing.B) {
+			var mu sync.Mutex
+			symbols := benchSymbols(n)
+			states := make(map[string]TickerState, n)
+			for _, symbol := range symbols {
+				states[symbol] = TickerState{}
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				mu.Lock()
+				for _, symbol := range symbols {
+					s := states[symbol]
+					s.LatestTickerData.LastPrice++
+					states[symbol] = s
+				}
+				mu.Unlock()
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"path"
+	"regexp"
+)
+
+// ConfigError описывает одну ошибку конфигурации. Field — путь к полю в JSON, например "tiers[1].level".
+type ConfigError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e ConfigError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+const maxMinutes = 24 * 60
+
+// maxLookbackMinutes — предел окна отсчета. История цен хранит запись на каждую секунду окна
+// для каждого символа, а Extremes обходит окно на каждом тике, поэтому окна длиннее часа не допускаются.
+const maxLookbackMinutes = 60
+
+type configErrors []ConfigError
+
+func (errs *configErrors) add(field, format string, args ...any) {
+	*errs = append(*errs, ConfigError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate проверяет конфигурацию целиком и возвращает все найденные ошибки.
+// Шаблоны вебхуков проверяет notifier — см. client.ValidateConfig.
+func (c EngineConfig) Validate() []ConfigError {
+	var errs configErrors
+
+	if c.Window < 0 || c.Window > maxLookbackMinutes {
+		errs.add("window", "must be between 0 and %d minutes", maxLookbackMinutes)
+	}
+
+	if c.StaleAfter < 0 || c.StaleAfter > 3600 {
+		errs.add("staleAfter", "must be between 0 and 3600 seconds")
+	}
+
+	if len(c.Tiers) == 0 {
+		errs.add("tiers", "at least one tier is required")
+	}
+	validateTiers(&errs, "tiers", c.Tiers)
+
+	for i, o := range c.Overrides {
+		field := fmt.Sprintf("overrides[%d]", i)
+		if len(o.Symbols) == 0 && len(o.Patterns) == 0 && o.MinVolume <= 0 && o.MaxVolume <= 0 {
+			errs.add(field, "rule must set symbols, patterns or a volume range")
+		}
+		for j, p := range o.Patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				errs.add(fmt.Sprintf("%s.patterns[%d]", field, j), "invalid glob pattern %q", p)
+			}
+		}
+		if o.MinVolume < 0 || o.MaxVolume < 0 {
+			errs.add(field+".minVolume", "volume bounds must not be negative")
+		} else if o.MaxVolume > 0 && o.MaxVolume <= o.MinVolume {
+			errs.add(field+".maxVolume", "must be greater than minVolume")
+		}
+		validateTiers(&errs, field+".tiers", o.Tiers)
+	}
+
+	validateRegexps(&errs, "filter.includeRegex", c.Filter.IncludeRegex)
+	validateRegexps(&errs, "filter.excludeRegex", c.Filter.ExcludeRegex)
+	if c.Filter.MinVolume < 0 {
+		errs.add("filter.minVolume", "must not be negative")
+	}
+
+	if a := c.Adaptive; a.Enabled {
+		if a.Baseline <= 0 {
+			errs.add("adaptive.baseline", "must be positive")
+		}
+		if a.MinScale < 0 || a.MaxScale < 0 {
+			errs.add("adaptive.minScale", "scale bounds must not be negative")
+		} else if a.MaxScale > 0 && a.MaxScale < a.MinScale {
+			errs.add("adaptive.maxScale", "must not be less than minScale")
+		}
+	}
+
+	s := c.Sanity
+	if s.MaxJump < 0 || math.IsNaN(s.MaxJump) {
+		errs.add("sanity.maxJump", "must not be negative")
+	}
+	if s.MedianWindow != 0 && (s.MedianWindow < 3 || s.MedianWindow > MaxMedianWindow) {
+		errs.add("sanity.medianWindow", "must be 0 or between 3 and %d prints", MaxMedianWindow)
+	}
+	if s.ConfirmUpdates < 0 || s.ConfirmUpdates > 100 {
+		errs.add("sanity.confirmUpdates", "must be between 0 and 100")
+	}
+
+	p := c.Paper
+	if p.PositionSize < 0 {
+		errs.add("paper.positionSize", "must not be negative")
+	} else if p.PositionSize == 0 && c.usesPaperTrading() {
+		errs.add("paper.positionSize", "must be positive when paper trading is enabled")
+	}
+	if p.FeeRate < 0 || p.FeeRate >= 100 {
+		errs.add("paper.feeRate", "must be between 0 and 100 percent")
+	}
+	if p.Slippage < 0 || p.Slippage >= 100 {
+		errs.add("paper.slippage", "must be between 0 and 100 percent")
+	}
+	if p.StopLoss < 0 || p.StopLoss >= 100 {
+		errs.add("paper.stopLoss", "must be between 0 and 100 percent")
+	}
+
+	for i, h := range c.Webhooks {
+		field := fmt.Sprintf("webhooks[%d]", i)
+		u, err := url.Parse(h.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.add(field+".url", "must be an absolute http(s) URL")
+		}
+		for j, st := range h.Statuses {
+			switch st {
+			case StatusActive, StatusReturned, StatusTimeout, StatusDismissed, StatusCancelled:
+			default:
+				errs.add(fmt.Sprintf("%s.statuses[%d]", field, j), "unknown status %q", st)
+			}
+		}
+	}
+
+	return errs
+}
+
+func (c EngineConfig) usesPaperTrading() bool {
+	for _, t := range c.Tiers {
+		if t.PaperTrade {
+			return true
+		}
+	}
+	for _, o := range c.Overrides {
+		for _, t := range o.Tiers {
+			if t.PaperTrade {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateTiers проверяет набор тиров. Уровни сравниваются после округления: в splash_records
+// уровень хранится целым, и два тира с одним уровнем в пересекающихся направлениях неразличимы.
+func validateTiers(errs *configErrors, prefix string, tiers []SplashTier) {
+	for i, t := range tiers {
+		field := fmt.Sprintf("%s[%d]", prefix, i)
+
+		if t.Level <= 0 || t.Level >= 100 || math.IsNaN(t.Level) {
+			errs.add(field+".level", "must be between 0 and 100 percent")
+		} else if math.Round(t.Level) < 1 {
+			errs.add(field+".level", "must be at least 1 percent after rounding")
+		}
+		if t.Window <= 0 || t.Window > maxMinutes {
+			errs.add(field+".window", "must be between 1 and %d minutes", maxMinutes)
+		}
+		if t.Lookback < 0 || t.Lookback > maxLookbackMinutes {
+			errs.add(field+".lookback", "must be between 0 and %d minutes", maxLookbackMinutes)
+		}
+
+		switch t.Direction {
+		case "", DirectionBoth, DirectionUp, DirectionDown:
+		default:
+			errs.add(field+".direction", "unknown direction %q", t.Direction)
+		}
+
+		switch t.ToleranceMode {
+		case "", ToleranceAuto, TolerancePercent, ToleranceATR:
+		case ToleranceFraction:
+			if t.Tolerance >= 1 {
+				errs.add(field+".tolerance", "fraction must be less than 1")
+			}
+		default:
+			errs.add(field+".toleranceMode", "unknown tolerance mode %q", t.ToleranceMode)
+		}
+		if t.Tolerance < 0 {
+			errs.add(field+".tolerance", "must not be negative")
+		} else if t.ToleranceMode == TolerancePercent && t.Level > 0 && t.Tolerance >= t.Level {
+			errs.add(field+".tolerance", "must be below the tier level")
+		}
+
+		for j := 0; j < i; j++ {
+			prev := tiers[j]
+			if math.Round(prev.Level) == math.Round(t.Level) && directionsOverlap(prev.Direction, t.Direction) {
+				errs.add(field+".level", "duplicates %s[%d]", prefix, j)
+				break
+			}
+		}
+	}
+}
+
+func directionsOverlap(a, b string) bool {
+	if a == "" || a == DirectionBoth || b == "" || b == DirectionBoth {
+		return true
+	}
+	return a == b
+}
+
+func validateRegexps(errs *configErrors, prefix string, patterns []string) {
+	for i, p := range patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			errs.add(fmt.Sprintf("%s[%d]", prefix, i), "invalid regular expression: %v", err)
+		}
+	}
+}
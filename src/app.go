@@ -2,27 +2,276 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"splash-trading-bot/database"
 	"splash-trading-bot/lib/models"
 	"splash-trading-bot/src/client"
+	"splash-trading-bot/src/notifier"
+	"splash-trading-bot/src/recorder"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+const (
+	defaultRecordDir = "data/ticks"
+	defaultProfile   = "default"
+	postgresConnStr  = ""
+)
+
 type App struct {
 	ctx context.Context
+
+	recMu    sync.Mutex
+	recorder *recorder.Writer
+
+	profileMu   sync.Mutex
+	profile     string
+	configReady chan struct{}
 }
 
 func NewApp() *App {
-	return &App{}
+	return &App{
+		profile:     defaultProfile,
+		configReady: make(chan struct{}),
+	}
 }
 
-// startup вызывается при запуске приложения.
+// startup вызывается при запуске приложения. Опрос биржи стартует только после загрузки
+// активного профиля, чтобы детектор не успел поработать на встроенных тирах.
 func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
-	go client.StartPolling(a.ctx)
+	go func() {
+		if err := database.InitDatabase(postgresConnStr); err != nil {
+			runtime.LogErrorf(a.ctx, "Database is unavailable: %v", err)
+		}
+		a.loadActiveProfile()
+		if models.Config().Version == 0 {
+			// встроенная конфигурация тоже получает версию, чтобы сплеши без профиля не остались без ссылки
+			client.ApplyConfig(*models.Config())
+		}
+		close(a.configReady)
+
+		resumed, expired, err := client.RecoverActiveSplashes(client.EngineClock.Now())
+		if err != nil {
+			runtime.LogErrorf(a.ctx, "Splash recovery failed: %v", err)
+		} else if resumed+expired > 0 {
+			runtime.LogInfof(a.ctx, "Splash recovery: %d resumed, %d closed as timeout", resumed, expired)
+		}
+
+		if err := client.Start(a.ctx); err != nil {
+			runtime.LogErrorf(a.ctx, "Failed to start engine: %v", err)
+		}
+	}()
+}
+
+// Shutdown вызывается при закрытии окна: останавливает опрос, закрывает активные сплеши
+// со статусом CANCELLED, дописывает запись тиков и останавливает воркеры состояния.
+func (a *App) Shutdown(ctx context.Context) {
+	client.Stop()
+	// вебхуки, отправленные при остановленном движке, Stop уже не дожидается
+	notifier.Shutdown(client.NotifyDrainTimeout)
+	a.StopRecording()
+	client.TockenState.Close()
+}
+
+func (a *App) StartEngine() error {
+	select {
+	case <-a.configReady:
+	default:
+		return fmt.Errorf("engine is still starting")
+	}
+	return client.Start(a.ctx)
+}
+
+func (a *App) PauseEngine() error {
+	return client.Pause()
+}
+
+func (a *App) ResumeEngine() error {
+	return client.Resume()
 }
 
-func (a *App) UpdateConfig(config models.EngineConfig) {
-	models.CurrentConfig = config
+// StopEngine останавливает опрос и закрывает активные сплеши со статусом CANCELLED.
+func (a *App) StopEngine() error {
+	return client.Stop()
+}
+
+func (a *App) EngineState() string {
+	return client.EngineState()
+}
+
+// GetFeedHealth возвращает состояние ленты тикеров; обновления также приходят событием feed:health.
+func (a *App) GetFeedHealth() models.FeedHealth {
+	return client.FeedHealth()
+}
+
+func (a *App) loadActiveProfile() {
+	profile, err := database.GetActiveConfigProfile()
+	if err != nil {
+		if !errors.Is(err, database.ErrProfileNotFound) {
+			runtime.LogErrorf(a.ctx, "Failed to load config profile: %v", err)
+		}
+		return
+	}
+
+	if errs := client.ApplyConfig(profile.Config); len(errs) > 0 {
+		runtime.LogErrorf(a.ctx, "Config profile %q is invalid, using defaults: %v", profile.Name, errs)
+		return
+	}
+	a.profileMu.Lock()
+	a.profile = profile.Name
+	a.profileMu.Unlock()
+	runtime.LogInfof(a.ctx, "Config profile %q loaded, %d tiers", profile.Name, len(profile.Config.Tiers))
+}
+
+// GetConfig возвращает текущую конфигурацию движка, дождавшись загрузки профиля при старте.
+func (a *App) GetConfig() models.EngineConfig {
+	select {
+	case <-a.configReady:
+	case <-time.After(10 * time.Second):
+	}
+	return *models.Config()
+}
+
+// UpdateConfig проверяет и применяет конфигурацию, затем сохраняет ее в активный профиль.
+// При ошибках проверки конфигурация не меняется, а ошибки возвращаются в UI.
+func (a *App) UpdateConfig(config models.EngineConfig) []models.ConfigError {
+	if errs := client.ApplyConfig(config); len(errs) > 0 {
+		runtime.LogWarningf(a.ctx, "Config rejected: %d validation errors", len(errs))
+		return errs
+	}
 	runtime.LogInfof(a.ctx, "Config successfully updated, %v levels updated", len(config.Tiers))
+
+	name := a.ActiveProfile()
+	if err := database.SaveConfigProfile(name, config); err != nil {
+		runtime.LogErrorf(a.ctx, "Failed to persist config profile %q: %v", name, err)
+	}
+	return nil
+}
+
+func (a *App) ActiveProfile() string {
+	a.profileMu.Lock()
+	defer a.profileMu.Unlock()
+	return a.profile
+}
+
+func (a *App) ListProfiles() ([]models.ConfigProfile, error) {
+	return database.ListConfigProfiles()
+}
+
+// LoadProfile применяет сохраненный профиль и делает его активным.
+func (a *App) LoadProfile(name string) (models.EngineConfig, error) {
+	profile, err := database.GetConfigProfile(name)
+	if err != nil {
+		return models.EngineConfig{}, err
+	}
+	if errs := client.ValidateConfig(profile.Config); len(errs) > 0 {
+		return models.EngineConfig{}, fmt.Errorf("profile %q is invalid: %v", name, errs[0])
+	}
+	if err := database.ActivateConfigProfile(name); err != nil {
+		return models.EngineConfig{}, err
+	}
+
+	client.ApplyConfig(profile.Config)
+	a.profileMu.Lock()
+	a.profile = name
+	a.profileMu.Unlock()
+	runtime.LogInfof(a.ctx, "Config profile %q loaded", name)
+	return profile.Config, nil
+}
+
+// SaveProfile проверяет конфигурацию, сохраняет ее под именем name, применяет и делает профиль активным.
+func (a *App) SaveProfile(name string, config models.EngineConfig) ([]models.ConfigError, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("profile name is empty")
+	}
+	if errs := client.ValidateConfig(config); len(errs) > 0 {
+		return errs, nil
+	}
+	if err := database.SaveConfigProfile(name, config); err != nil {
+		return nil, err
+	}
+
+	client.ApplyConfig(config)
+	a.profileMu.Lock()
+	a.profile = name
+	a.profileMu.Unlock()
+	runtime.LogInfof(a.ctx, "Config profile %q saved", name)
+	return nil, nil
+}
+
+// DeleteProfile удаляет профиль вместе с историей. Активный профиль удалить нельзя.
+func (a *App) DeleteProfile(name string) error {
+	if name == a.ActiveProfile() {
+		return fmt.Errorf("cannot delete active profile %q", name)
+	}
+	return database.DeleteConfigProfile(name)
+}
+
+func (a *App) GetProfileHistory(name string) ([]models.ConfigRevision, error) {
+	return database.GetConfigHistory(name)
+}
+
+func (a *App) GetPaperStats() models.PaperStats {
+	stats, err := database.GetPaperStats()
+	if err != nil {
+		runtime.LogErrorf(a.ctx, "Failed to load paper stats: %v", err)
+	}
+	return stats
+}
+
+// StartRecording включает запись всех снимков тикеров в каталог dir (по умолчанию data/ticks).
+func (a *App) StartRecording(dir string) error {
+	a.recMu.Lock()
+	defer a.recMu.Unlock()
+
+	if a.recorder != nil {
+		return nil
+	}
+	if dir == "" {
+		dir = defaultRecordDir
+	}
+
+	w, err := recorder.NewWriter(recorder.Options{Dir: dir})
+	if err != nil {
+		return err
+	}
+	a.recorder = w
+	client.SetRecorder(w)
+	runtime.LogInfof(a.ctx, "Tick recording started: %s", dir)
+	return nil
+}
+
+func (a *App) StopRecording() error {
+	a.recMu.Lock()
+	defer a.recMu.Unlock()
+
+	if a.recorder == nil {
+		return nil
+	}
+	client.SetRecorder(nil)
+	err := a.recorder.Close()
+	a.recorder = nil
+	runtime.LogInfo(a.ctx, "Tick recording stopped")
+	return err
+}
+
+func (a *App) IsRecording() bool {
+	a.recMu.Lock()
+	defer a.recMu.Unlock()
+	return a.recorder != nil
+}
+
+// DismissSplash снимает активный сплеш символа, в том числе закрепленный.
+func (a *App) DismissSplash(symbol string) error {
+	if err := client.DismissSplash(symbol); err != nil {
+		return err
+	}
+	runtime.LogInfof(a.ctx, "Splash dismissed: %s", symbol)
+	return nil
 }
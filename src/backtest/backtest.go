@@ -0,0 +1,138 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"splash-trading-bot/lib/clock"
+	"splash-trading-bot/lib/models"
+	"splash-trading-bot/src/client"
+	"splash-trading-bot/src/recorder"
+	"time"
+)
+
+type Report struct {
+	Frames           int           `json:"frames"`
+	From             time.Time     `json:"from"`
+	To               time.Time     `json:"to"`
+	Signals          int           `json:"signals"`
+	Returned         int           `json:"returned"`
+	Timeouts         int           `json:"timeouts"`
+	Open             int           `json:"open"`
+	DataGaps         int           `json:"dataGaps"`
+	ReturnRate       float64       `json:"returnRate"`
+	MedianReturnTime time.Duration `json:"medianReturnTime"`
+	PaperTrades      int           `json:"paperTrades"`
+	PaperWins        int           `json:"paperWins"`
+	PaperPnL         float64       `json:"paperPnl"`
+	ConfigHash       string        `json:"configHash"`
+}
+
+type countingSource struct {
+	client.TickerSource
+	frames   int
+	from, to time.Time
+}
+
+func (s *countingSource) Next(ctx context.Context) ([]models.SplashData, time.Time, error) {
+	tickers, at, err := s.TickerSource.Next(ctx)
+	if err == nil {
+		if s.frames == 0 {
+			s.from = at
+		}
+		s.frames++
+		s.to = at
+	}
+	return tickers, at, err
+}
+
+// Run воспроизводит запись (файл или каталог recorder) через RunPolling/CheckPrices/StepTracking
+// с симулированными часами и хранилищем в памяти, без вебхуков. Движок хранит состояние в глобальных переменных,
+// поэтому в одном процессе допускается только один прогон.
+func Run(ctx context.Context, dataPath string, cfg models.EngineConfig) (Report, error) {
+	store := newMemoryStore()
+	client.Store = store
+	// воспроизведение не должно слать алерты в настоящие вебхуки
+	cfg.Webhooks = nil
+	if errs := client.ApplyConfig(cfg); len(errs) > 0 {
+		return Report{}, fmt.Errorf("invalid config: %w", errors.Join(configErrs(errs)...))
+	}
+
+	reader, err := recorder.Open(dataPath)
+	if err != nil {
+		return Report{}, err
+	}
+	defer reader.Close()
+
+	clk := clock.NewFake(time.Time{})
+
+	models.AppCtx = nil
+	client.EngineClock = clk
+
+	source := &countingSource{TickerSource: NewReplaySource(reader, clk)}
+	client.RunPolling(ctx, source)
+	if err := ctx.Err(); err != nil {
+		return Report{}, err
+	}
+
+	client.FlushRecords()
+	records, trades := store.snapshot()
+	report := buildReport(records, trades)
+	report.Frames = source.frames
+	report.From = source.from
+	report.To = source.to
+	report.ConfigHash = cfg.Fingerprint()
+	return report, nil
+}
+
+func configErrs(errs []models.ConfigError) []error {
+	out := make([]error, len(errs))
+	for i, e := range errs {
+		out[i] = e
+	}
+	return out
+}
+
+func buildReport(records []models.SplashRecord, trades []models.PaperTrade) Report {
+	var report Report
+	var returnTimes []time.Duration
+
+	for _, r := range records {
+		report.Signals++
+		if r.DataGap {
+			report.DataGaps++
+		}
+		switch r.Status {
+		case models.StatusReturned:
+			report.Returned++
+			returnTimes = append(returnTimes, r.ReturnTime)
+		case models.StatusTimeout:
+			report.Timeouts++
+		default:
+			report.Open++
+		}
+	}
+
+	if closed := report.Returned + report.Timeouts; closed > 0 {
+		report.ReturnRate = float64(report.Returned) / float64(closed)
+	}
+
+	if len(returnTimes) > 0 {
+		sort.Slice(returnTimes, func(i, j int) bool { return returnTimes[i] < returnTimes[j] })
+		mid := len(returnTimes) / 2
+		report.MedianReturnTime = returnTimes[mid]
+		if len(returnTimes)%2 == 0 {
+			report.MedianReturnTime = (returnTimes[mid-1] + returnTimes[mid]) / 2
+		}
+	}
+
+	for _, t := range trades {
+		report.PaperTrades++
+		report.PaperPnL += t.PnL
+		if t.PnL > 0 {
+			report.PaperWins++
+		}
+	}
+	return report
+}
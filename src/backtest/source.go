@@ -0,0 +1,32 @@
+package backtest
+
+import (
+	"context"
+	"splash-trading-bot/lib/clock"
+	"splash-trading-bot/lib/models"
+	"splash-trading-bot/src/recorder"
+	"time"
+)
+
+// ReplaySource отдает снимки из записи и переводит симулированные часы на время каждого снимка.
+type ReplaySource struct {
+	reader *recorder.Reader
+	clock  *clock.Fake
+}
+
+func NewReplaySource(reader *recorder.Reader, clock *clock.Fake) *ReplaySource {
+	return &ReplaySource{reader: reader, clock: clock}
+}
+
+func (s *ReplaySource) Next(ctx context.Context) ([]models.SplashData, time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	frame, err := s.reader.Next()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	s.clock.Set(frame.Time)
+	return frame.Tickers, frame.Time, nil
+}
@@ -0,0 +1,169 @@
+package backtest
+
+import (
+	"fmt"
+	"splash-trading-bot/lib/models"
+	"sync"
+	"time"
+)
+
+type storedRecord struct {
+	record   models.SplashRecord
+	basisGap float64
+	speed    float64
+}
+
+// memoryStore повторяет семантику таблиц splash_records и paper_trades в памяти,
+// чтобы воспроизведение не трогало боевую базу.
+type memoryStore struct {
+	mu      sync.Mutex
+	nextID  int64
+	records []*storedRecord
+	byID    map[int64]*storedRecord
+	trades  []models.PaperTrade
+	configs map[string]int64
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		byID:    make(map[int64]*storedRecord),
+		configs: make(map[string]int64),
+	}
+}
+
+func (s *memoryStore) GetContextStats(direction string, level int, volume float64, basisGap float64, window int, toleranceMode string, asOf time.Time) (int, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	volMin, volMax := volume*0.5, volume*2
+	total, wins := 0, 0
+	for _, r := range s.records {
+		rec := r.record
+		if rec.Direction != direction || rec.TriggerLevel != level || rec.TimeWindow != window || rec.ToleranceMode != toleranceMode {
+			continue
+		}
+		if rec.Volume24h < volMin || rec.Volume24h > volMax {
+			continue
+		}
+		if r.basisGap < basisGap-0.5 || r.basisGap > basisGap+0.5 {
+			continue
+		}
+		if rec.Status == models.StatusCancelled {
+			continue
+		}
+		window := time.Duration(rec.TimeWindow) * time.Minute
+		expired := rec.TriggerTime.Before(asOf.Add(-window))
+		finished := rec.Status == models.StatusReturned || rec.Status == models.StatusTimeout
+		if !finished && !expired {
+			continue
+		}
+
+		total++
+		if rec.Status == models.StatusReturned && rec.ReturnTime <= window {
+			wins++
+		}
+	}
+	return total, wins, nil
+}
+
+func (s *memoryStore) SaveSplashRecord(r models.SplashRecord, basisGap float64, speedSeconds float64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.records {
+		rec := existing.record
+		if rec.Status == models.StatusActive && rec.Symbol == r.Symbol && rec.TriggerLevel == r.TriggerLevel {
+			return 0, fmt.Errorf("failed to insert splash record: active splash already exists")
+		}
+	}
+
+	s.nextID++
+	r.ID = int(s.nextID)
+	r.Status = models.StatusActive
+	stored := &storedRecord{record: r, basisGap: basisGap, speed: speedSeconds}
+	s.records = append(s.records, stored)
+	s.byID[s.nextID] = stored
+	return s.nextID, nil
+}
+
+func (s *memoryStore) UpdateSplashLevel(id int64, level int, lastPrice float64, fairPrice float64, volume24 float64, probWin float64, window int, toleranceMode string, tolerance float64, forcedPin bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.byID[id]
+	if !ok {
+		return fmt.Errorf("record with ID %d not found", id)
+	}
+	stored.record.TriggerLevel = level
+	stored.record.TriggerLastPrice = lastPrice
+	stored.record.TriggerFairPrice = fairPrice
+	stored.record.Volume24h = volume24
+	stored.record.LongProbability = probWin
+	stored.record.TimeWindow = window
+	stored.record.ToleranceMode = toleranceMode
+	stored.record.Tolerance = tolerance
+	stored.record.ForcedPin = stored.record.ForcedPin || forcedPin
+	return nil
+}
+
+func (s *memoryStore) GetActiveSplashRecords() ([]models.SplashRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var active []models.SplashRecord
+	for i := len(s.records) - 1; i >= 0; i-- {
+		if r := s.records[i].record; r.Status == models.StatusActive {
+			active = append(active, r)
+		}
+	}
+	return active, nil
+}
+
+func (s *memoryStore) CloseSplashRecord(id int64, status string, returnTime time.Duration, maxDeviation float64, dataGap bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.byID[id]
+	if !ok {
+		return fmt.Errorf("cannot update splash record with ID %d", id)
+	}
+	stored.record.Returned = status == models.StatusReturned
+	stored.record.Status = status
+	stored.record.ReturnTime = returnTime
+	stored.record.MaxDeviation = maxDeviation
+	stored.record.DataGap = stored.record.DataGap || dataGap
+	return nil
+}
+
+func (s *memoryStore) SavePaperTrade(t models.PaperTrade) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t.ID = int64(len(s.trades) + 1)
+	s.trades = append(s.trades, t)
+	return t.ID, nil
+}
+
+func (s *memoryStore) SaveConfigVersion(hash string, cfg models.EngineConfig) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id, ok := s.configs[hash]; ok {
+		return id, nil
+	}
+	id := int64(len(s.configs) + 1)
+	s.configs[hash] = id
+	return id, nil
+}
+
+func (s *memoryStore) snapshot() ([]models.SplashRecord, []models.PaperTrade) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make([]models.SplashRecord, 0, len(s.records))
+	for _, r := range s.records {
+		records = append(records, r.record)
+	}
+	trades := append([]models.PaperTrade(nil), s.trades...)
+	return records, trades
+}
@@ -0,0 +1,181 @@
+package backtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"splash-trading-bot/lib/models"
+	"strings"
+)
+
+// Grid описывает набор конфигураций для перебора. Тиры строятся как декартово произведение
+// Levels x Windows x Lookbacks x Tolerances; Configs добавляются как есть. Каждая запись Configs
+// разбирается поверх models.DefaultConfig, как конфигурация replay.
+type Grid struct {
+	Base       models.EngineConfig   `json:"base"`
+	Levels     [][]float64           `json:"levels"`
+	Windows    []int                 `json:"windows"`
+	Lookbacks  []int                 `json:"lookbacks"`
+	Tolerances []GridTolerance       `json:"tolerances"`
+	PaperTrade bool                  `json:"paperTrade"`
+	Configs    []models.EngineConfig `json:"configs"`
+}
+
+func (g *Grid) UnmarshalJSON(data []byte) error {
+	type plain Grid
+	raw := struct {
+		*plain
+		Configs []json.RawMessage `json:"configs"`
+	}{plain: (*plain)(g)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	g.Configs = nil
+	for i, entry := range raw.Configs {
+		cfg := models.DefaultConfig()
+		if err := json.Unmarshal(entry, &cfg); err != nil {
+			return fmt.Errorf("configs[%d]: %w", i, err)
+		}
+		g.Configs = append(g.Configs, cfg)
+	}
+	return nil
+}
+
+type GridTolerance struct {
+	Mode  string  `json:"mode"`
+	Value float64 `json:"value"`
+}
+
+type Candidate struct {
+	Label  string              `json:"label"`
+	Config models.EngineConfig `json:"config"`
+}
+
+type SweepResult struct {
+	Candidate
+	Report Report `json:"report"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (g Grid) Expand() []Candidate {
+	var out []Candidate
+
+	windows := g.Windows
+	if len(windows) == 0 {
+		windows = []int{0}
+	}
+	lookbacks := g.Lookbacks
+	if len(lookbacks) == 0 {
+		lookbacks = []int{0}
+	}
+	tolerances := g.Tolerances
+	if len(tolerances) == 0 {
+		tolerances = []GridTolerance{{}}
+	}
+
+	for _, levels := range g.Levels {
+		for _, window := range windows {
+			for _, lookback := range lookbacks {
+				for _, tol := range tolerances {
+					cfg := g.Base
+					cfg.Tiers = nil
+					for _, level := range levels {
+						tier := models.SplashTier{
+							Level:         level,
+							Window:        window,
+							Lookback:      lookback,
+							PaperTrade:    g.PaperTrade,
+							ToleranceMode: tol.Mode,
+							Tolerance:     tol.Value,
+						}
+						if window == 0 {
+							tier.Window = baseWindow(g.Base, level)
+						}
+						cfg.Tiers = append(cfg.Tiers, tier)
+					}
+					out = append(out, Candidate{Label: tiersLabel(cfg.Tiers), Config: cfg})
+				}
+			}
+		}
+	}
+
+	for _, cfg := range g.Configs {
+		out = append(out, Candidate{Label: tiersLabel(cfg.Tiers), Config: cfg})
+	}
+	return out
+}
+
+// baseWindow берет окно возврата из базовой конфигурации для ближайшего тира не выше level.
+func baseWindow(base models.EngineConfig, level float64) int {
+	window := 5
+	best := -1.0
+	for _, t := range base.Tiers {
+		if t.Level <= level && t.Level > best {
+			best = t.Level
+			window = t.Window
+		}
+	}
+	return window
+}
+
+func tiersLabel(tiers []models.SplashTier) string {
+	parts := make([]string, 0, len(tiers))
+	for _, t := range tiers {
+		part := fmt.Sprintf("%g%%/%dm", t.Level, t.Window)
+		if t.Direction != "" && t.Direction != models.DirectionBoth {
+			part += "/" + strings.ToLower(t.Direction)
+		}
+		if t.Lookback > 0 {
+			part += fmt.Sprintf("/lb%dm", t.Lookback)
+		}
+		if t.Tolerance > 0 && t.ToleranceMode != "" && t.ToleranceMode != models.ToleranceAuto {
+			part += fmt.Sprintf("/%s:%g", t.ToleranceMode, t.Tolerance)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+var RankKeys = []string{"pnl", "returnRate", "signals", "medianReturn"}
+
+// Rank сортирует результаты по выбранной метрике; при равенстве — по P&L и доле возвратов.
+// Прогоны с ошибкой всегда в конце.
+func Rank(results []SweepResult, by string) error {
+	var less func(a, b Report) bool
+	switch by {
+	case "pnl":
+		less = func(a, b Report) bool { return a.PaperPnL > b.PaperPnL }
+	case "returnRate":
+		less = func(a, b Report) bool { return a.ReturnRate > b.ReturnRate }
+	case "signals":
+		less = func(a, b Report) bool { return a.Signals > b.Signals }
+	case "medianReturn":
+		less = func(a, b Report) bool {
+			if (a.Returned == 0) != (b.Returned == 0) {
+				return a.Returned != 0
+			}
+			return a.MedianReturnTime < b.MedianReturnTime
+		}
+	default:
+		return fmt.Errorf("unknown rank key %q (use one of %s)", by, strings.Join(RankKeys, ", "))
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if (a.Error == "") != (b.Error == "") {
+			return a.Error == ""
+		}
+		if less(a.Report, b.Report) {
+			return true
+		}
+		if less(b.Report, a.Report) {
+			return false
+		}
+		if a.Report.PaperPnL != b.Report.PaperPnL {
+			return a.Report.PaperPnL > b.Report.PaperPnL
+		}
+		return a.Report.ReturnRate > b.Report.ReturnRate
+	})
+	return nil
+}
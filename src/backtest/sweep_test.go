@@ -0,0 +1,38 @@
+package backtest
+
+import (
+	"encoding/json"
+	"reflect"
+	"splash-trading-bot/lib/models"
+	"testing"
+)
+
+func TestGridStartsFromDefaultConfig(t *testing.T) {
+	raw := `{
+		"base": { "paper": { "positionSize": 250 } },
+		"levels": [[3]],
+		"configs": [{ "tiers": [{ "level": 4, "window": 5 }] }]
+	}`
+	grid := Grid{Base: models.DefaultConfig()}
+	if err := json.Unmarshal([]byte(raw), &grid); err != nil {
+		t.Fatal(err)
+	}
+
+	candidates := grid.Expand()
+	if len(candidates) != 2 {
+		t.Fatalf("candidates = %d, want 2", len(candidates))
+	}
+	def := models.DefaultConfig()
+	for _, c := range candidates {
+		cfg := c.Config
+		if cfg.Sanity != def.Sanity || cfg.StaleAfter != def.StaleAfter || !reflect.DeepEqual(cfg.Filter, def.Filter) {
+			t.Errorf("%s: sanity %+v, staleAfter %d, filter %+v, want the defaults", c.Label, cfg.Sanity, cfg.StaleAfter, cfg.Filter)
+		}
+	}
+	if got := candidates[0].Config.Paper; got.PositionSize != 250 || got.StopLoss != def.Paper.StopLoss {
+		t.Errorf("base paper = %+v, want position size 250 over the defaults", got)
+	}
+	if tiers := candidates[1].Config.Tiers; len(tiers) != 1 || tiers[0].Level != 4 {
+		t.Errorf("configs[0] tiers = %+v, want the tiers from the grid", tiers)
+	}
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"fmt"
+	"math/rand"
+	"splash-trading-bot/lib/models"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// BenchmarkFrame измеряет обработку одного снимка (ProcessTickers + StepTracking) на синтетическом рынке,
+// где пятая часть символов держит активный сплеш, который так и не вернется. readers горутин
+// параллельно запрашивают состояние символов у воркеров, как это делает UI.
+//
+//	go test -run '^$' -bench Frame -benchmem ./src/client
+func BenchmarkFrame(b *testing.B) {
+	for _, n := range []int{1000, 5000} {
+		for _, readers := range []int{0, 4} {
+			b.Run(fmt.Sprintf("symbols=%d/readers=%d", n, readers), func(b *testing.B) {
+				benchFrame(b, n, 0.2, readers)
+			})
+		}
+	}
+}
+
+func benchFrame(b *testing.B, n int, share float64, readers int) {
+	e := newTestEngine(b, models.SplashTier{Level: 1, Window: 1440})
+
+	rng := rand.New(rand.NewSource(1))
+	symbols := make([]string, n)
+	base := make([]float64, n)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("SYM%05d_USDT", i)
+		base[i] = 1 + rng.Float64()*100
+	}
+	jumped := int(float64(n) * share)
+
+	frame := make([]models.SplashData, n)
+	step := func(jump bool) {
+		e.clock.Advance(100 * time.Millisecond)
+		for i := range frame {
+			price := base[i] * (1 + (rng.Float64()-0.5)*0.0005)
+			if jump && i < jumped {
+				price *= 1.02
+			}
+			frame[i] = models.SplashData{Symbol: symbols[i], LastPrice: price, FairPrice: price, Volume24: 1e6}
+		}
+		ProcessTickers(frame, e.clock.Now())
+		StepTracking(e.clock.Now())
+	}
+
+	// разгон: история цен, затем скачок у части символов
+	for i := 0; i < 20; i++ {
+		step(false)
+	}
+	for i := 0; i < 5; i++ {
+		step(true)
+	}
+
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rr := rand.New(rand.NewSource(seed))
+			for !stop.Load() {
+				TockenState.Get(symbols[rr.Intn(n)])
+			}
+		}(int64(r))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		step(true)
+	}
+	b.StopTimer()
+	stop.Store(true)
+	wg.Wait()
+
+	b.ReportMetric(float64(n)*float64(b.N)/b.Elapsed().Seconds(), "symbols/s")
+	b.ReportMetric(float64(e.store.active()), "active")
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"fmt"
+	"log"
+	"splash-trading-bot/lib/models"
+	"splash-trading-bot/src/notifier"
+)
+
+// ValidateConfig проверяет конфигурацию движка вместе с шаблонами вебхуков.
+func ValidateConfig(cfg models.EngineConfig) []models.ConfigError {
+	errs := cfg.Validate()
+	for i, h := range cfg.Webhooks {
+		if err := notifier.ValidateTemplate(h.Template); err != nil {
+			errs = append(errs, models.ConfigError{Field: fmt.Sprintf("webhooks[%d].template", i), Message: err.Error()})
+		}
+	}
+	return errs
+}
+
+// ApplyConfig проверяет конфигурацию и, если ошибок нет, атомарно делает ее текущей.
+// Каждая примененная конфигурация регистрируется в хранилище как версия, id которой
+// пишется в сплеши. Если хранилище недоступно, конфигурация применяется с версией 0.
+func ApplyConfig(cfg models.EngineConfig) []models.ConfigError {
+	if errs := ValidateConfig(cfg); len(errs) > 0 {
+		return errs
+	}
+
+	cfg.Version = 0
+	hash := cfg.Fingerprint()
+	version, err := Store.SaveConfigVersion(hash, cfg)
+	if err != nil {
+		log.Printf("Failed to save config version %s: %v", hash[:12], err)
+	}
+	cfg.Version = version
+	models.SetConfig(cfg)
+	return nil
+}
@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"splash-trading-bot/lib/models"
+	"splash-trading-bot/src/notifier"
+	"splash-trading-bot/src/paper"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Состояния цикла опроса.
+const (
+	EngineStopped = "STOPPED"
+	EngineRunning = "RUNNING"
+	EnginePaused  = "PAUSED" // опрос остановлен, активные сплеши сохраняются до Resume
+)
+
+// NotifyDrainTimeout — сколько Stop ждет доставки оставшихся вебхуков, включая CANCELLED.
+const NotifyDrainTimeout = 5 * time.Second
+
+var engine = struct {
+	sync.Mutex
+	state  string
+	parent context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}{state: EngineStopped}
+
+// Start запускает опрос биржи в отдельной горутине. ctx — контекст приложения:
+// его отмена останавливает опрос так же, как Stop, но без закрытия сплешей.
+func Start(ctx context.Context) error {
+	engine.Lock()
+	defer engine.Unlock()
+
+	if engine.state != EngineStopped {
+		return fmt.Errorf("engine is already %s", engine.state)
+	}
+	models.AppCtx = ctx
+	engine.parent = ctx
+	startLoop()
+	setEngineState(EngineRunning)
+	return nil
+}
+
+// Pause останавливает опрос, не трогая активные сплеши. Пока движок на паузе, цены не обновляются,
+// поэтому возвраты и таймауты проверятся на первом снимке после Resume.
+func Pause() error {
+	engine.Lock()
+	defer engine.Unlock()
+
+	if engine.state != EngineRunning {
+		return fmt.Errorf("engine is %s", engine.state)
+	}
+	stopLoop()
+	setEngineState(EnginePaused)
+	return nil
+}
+
+func Resume() error {
+	engine.Lock()
+	defer engine.Unlock()
+
+	if engine.state != EnginePaused {
+		return fmt.Errorf("engine is %s", engine.state)
+	}
+	startLoop()
+	setEngineState(EngineRunning)
+	return nil
+}
+
+// Stop останавливает опрос, дожидается завершения цикла, закрывает активные сплеши со статусом CANCELLED
+// и останавливает доставку вебхуков, дав ей до NotifyDrainTimeout. Следующий сигнал снова запустит доставку.
+func Stop() error {
+	engine.Lock()
+	defer engine.Unlock()
+
+	if engine.state == EngineStopped {
+		return nil
+	}
+	stopLoop()
+	cancelled := CancelActiveSplashes(EngineClock.Now())
+	log.Printf("Terminus Engine: Offline, %d active splashes cancelled", cancelled)
+	FlushRecords()
+	notifier.Shutdown(NotifyDrainTimeout)
+	setEngineState(EngineStopped)
+	return nil
+}
+
+func EngineState() string {
+	engine.Lock()
+	defer engine.Unlock()
+	return engine.state
+}
+
+// startLoop и stopLoop вызываются под engine.
+func startLoop() {
+	ctx, cancel := context.WithCancel(engine.parent)
+	done := make(chan struct{})
+	engine.cancel = cancel
+	engine.done = done
+
+	go func() {
+		defer close(done)
+		StartPolling(ctx)
+	}()
+}
+
+func stopLoop() {
+	if engine.cancel == nil {
+		return
+	}
+	engine.cancel()
+	<-engine.done
+	engine.cancel = nil
+	engine.done = nil
+}
+
+func setEngineState(state string) {
+	engine.state = state
+	if models.AppCtx != nil {
+		runtime.EventsEmit(models.AppCtx, "engine:state", state)
+	}
+}
+
+// CancelActiveSplashes закрывает все сплеши на слежении со статусом CANCELLED и возвращает их число.
+// Цикл опроса к этому моменту должен быть остановлен.
+func CancelActiveSplashes(now time.Time) int {
+	cfg := models.Config()
+	var mu sync.Mutex
+	total := 0
+	TockenState.Broadcast(func(sh *models.StateShard) {
+		list := tracks[sh.Index]
+		cancelled := 0
+		for _, t := range list {
+			state, ok := sh.States[t.symbol]
+			settlePaperTrade(t.recordID, paper.ExitCancelled, state.LatestTickerData.LastPrice, now)
+			if !ok || state.SplashRecordID != t.recordID {
+				continue
+			}
+			emitSplashEvent(cfg, map[string]interface{}{
+				"symbol":      t.symbol,
+				"status":      models.StatusCancelled,
+				"isForcedPin": state.ForcedPin,
+			})
+			dataGap := nearGap(state, t.triggerTime, 0, cfg.StaleAfterDuration(), now)
+			SaveReturnBackRecord(t.recordID, models.StatusCancelled, now.Sub(t.triggerTime), t.maxDeviation, dataGap)
+			resetTickerState(sh, t.symbol)
+			cancelled++
+		}
+		mu.Lock()
+		total += cancelled
+		mu.Unlock()
+
+		clear(list)
+		tracks[sh.Index] = list[:0]
+	})
+	return total
+}
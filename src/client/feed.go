@@ -0,0 +1,121 @@
+package client
+
+import (
+	"errors"
+	"log"
+	"math/rand"
+	"splash-trading-bot/lib/models"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	feedBaseBackoff    = 200 * time.Millisecond
+	feedMaxBackoff     = 10 * time.Second
+	feedRateLimitPause = time.Second // пауза после 429 без Retry-After
+	breakerThreshold   = 5           // подряд неудачных запросов до размыкания цепи
+	breakerCooldown    = 30 * time.Second
+	feedEventInterval  = time.Second // не чаще раза в секунду, если статус не изменился
+)
+
+// ErrFeedBackoff возвращается вместо запроса, пока не прошла пауза после ошибки.
+var ErrFeedBackoff = errors.New("feed is backing off")
+
+var feed = struct {
+	sync.Mutex
+	health    models.FeedHealth
+	lastEvent time.Time
+}{health: models.FeedHealth{Status: models.FeedConnecting}}
+
+// FeedHealth возвращает текущее состояние опроса биржи.
+func FeedHealth() models.FeedHealth {
+	feed.Lock()
+	defer feed.Unlock()
+	return feed.health
+}
+
+// feedBreaker считает подряд идущие ошибки ленты и решает, когда можно слать следующий запрос.
+// После breakerThreshold ошибок цепь размыкается на breakerCooldown; первый запрос после паузы — пробный.
+type feedBreaker struct {
+	failures int
+	retryAt  time.Time
+}
+
+func (b *feedBreaker) allow(now time.Time) bool {
+	return !now.Before(b.retryAt)
+}
+
+func (b *feedBreaker) success(now time.Time, latency time.Duration) {
+	if b.failures > 0 {
+		log.Printf("Feed recovered after %d failed requests", b.failures)
+	}
+	b.failures = 0
+	b.retryAt = time.Time{}
+
+	updateFeedHealth(now, func(h *models.FeedHealth) {
+		h.Status = models.FeedOK
+		h.ConsecutiveFailures = 0
+		h.Requests++
+		h.LatencyMs = latency.Milliseconds()
+		h.LastSuccess = now
+		h.RetryAt = time.Time{}
+	})
+}
+
+func (b *feedBreaker) failure(now time.Time, err error) {
+	b.failures++
+	delay := jitteredBackoff(b.failures)
+	status := models.FeedDegraded
+
+	var rateLimit *RateLimitError
+	limited := errors.As(err, &rateLimit)
+	if limited {
+		status = models.FeedRateLimited
+		delay = max(delay, rateLimit.RetryAfter, feedRateLimitPause)
+	}
+	if b.failures >= breakerThreshold {
+		status = models.FeedDown
+		delay = max(delay, breakerCooldown)
+	}
+	b.retryAt = now.Add(delay)
+	log.Printf("Feed error (%d in a row, retry in %s): %v", b.failures, delay.Round(time.Millisecond), err)
+
+	updateFeedHealth(now, func(h *models.FeedHealth) {
+		h.Status = status
+		h.ConsecutiveFailures = b.failures
+		h.Requests++
+		h.Failures++
+		if limited {
+			h.RateLimited++
+		}
+		h.LastError = err.Error()
+		h.RetryAt = b.retryAt
+	})
+}
+
+// jitteredBackoff — экспоненциальная пауза с равномерным джиттером в верхней половине интервала.
+func jitteredBackoff(attempt int) time.Duration {
+	d := feedBaseBackoff << min(attempt-1, 16)
+	if d > feedMaxBackoff || d <= 0 {
+		d = feedMaxBackoff
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func updateFeedHealth(now time.Time, fn func(h *models.FeedHealth)) {
+	feed.Lock()
+	prev := feed.health.Status
+	fn(&feed.health)
+	health := feed.health
+	emit := health.Status != prev || now.Sub(feed.lastEvent) >= feedEventInterval
+	if emit {
+		feed.lastEvent = now
+	}
+	feed.Unlock()
+
+	if emit && models.AppCtx != nil {
+		runtime.EventsEmit(models.AppCtx, "feed:health", health)
+	}
+}
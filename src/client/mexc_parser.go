@@ -3,42 +3,50 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"math"
 	"net/http"
-	"splash-trading-bot/database"
 	"splash-trading-bot/lib/models"
-	"sync"
+	"splash-trading-bot/src/notifier"
+	"strconv"
 	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-var TockenState = &models.SharedState{
-	Mu:           sync.Mutex{},
-	TickerStates: make(map[string]models.TickerState),
-}
+var TockenState = models.NewSharedState()
+
+// Таймаут запроса держим коротким: пока запрос висит, цикл опроса не проверяет сплеши.
 var httpClient = &http.Client{
-	Timeout: 3 * time.Second,
+	Timeout: 2 * time.Second,
 }
 
-func GetNextSplash(currentChange float64, lastTriggeredLevel float64) (models.SplashTier, bool) {
+// GetNextSplash ищет среди tiers старший тир с окном lookback, уровень которого, умноженный на scale,
+// пробит изменением currentChange. Знак изменения задает направление: положительное — UP, отрицательное — DOWN.
+func GetNextSplash(cfg *models.EngineConfig, tiers []models.SplashTier, currentChange float64, lastTriggeredLevel float64, lookback time.Duration, scale float64) (models.SplashTier, bool) {
 	var triggeredLevel models.SplashTier
 	found := false
 
-	if len(models.CurrentConfig.Tiers) == 0 {
+	if len(tiers) == 0 {
 		return triggeredLevel, false
 	}
 
-	for _, tier := range models.CurrentConfig.Tiers {
-		if tier.Level <= 0 {
+	direction := models.DirectionUp
+	if currentChange < 0 {
+		direction = models.DirectionDown
+	}
+	change := math.Abs(currentChange)
+
+	for _, tier := range tiers {
+		if tier.Level <= 0 || cfg.TierLookback(tier) != lookback || !tier.Matches(direction) {
 			continue
 		}
 
 		targetRate := tier.Level / 100.0
-		if targetRate > lastTriggeredLevel && currentChange >= targetRate {
+		if targetRate > lastTriggeredLevel && change >= targetRate*scale {
 			if !found || tier.Level > triggeredLevel.Level {
 				triggeredLevel = tier
 				found = true
@@ -48,13 +56,35 @@ func GetNextSplash(currentChange float64, lastTriggeredLevel float64) (models.Sp
 	return triggeredLevel, found
 }
 
-func FetchAllFuturesTickers() ([]models.SplashData, error) {
-	resp, err := httpClient.Get(models.FuturesRestAPI)
+// mexcTooFrequent — код ошибки MEXC в теле ответа при превышении лимита запросов.
+const mexcTooFrequent = 510
+
+// RateLimitError — биржа ограничила частоту запросов. RetryAfter — пауза из заголовка Retry-After, 0 если его нет.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited, retry after %s", e.RetryAfter)
+	}
+	return "rate limited"
+}
+
+func FetchAllFuturesTickers(ctx context.Context) ([]models.SplashData, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, models.FuturesRestAPI, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("network error: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &RateLimitError{RetryAfter: retryAfter(resp.Header, EngineClock.Now())}
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("API error: status %d", resp.StatusCode)
 	}
@@ -69,93 +99,247 @@ func FetchAllFuturesTickers() ([]models.SplashData, error) {
 		log.Printf("JSON Decode Error: %v", err)
 		return nil, err
 	}
+	if !apiResponce.Success {
+		if apiResponce.Code == mexcTooFrequent {
+			return nil, &RateLimitError{RetryAfter: retryAfter(resp.Header, EngineClock.Now())}
+		}
+		return nil, fmt.Errorf("API error: code %d: %s", apiResponce.Code, apiResponce.Msg)
+	}
 	return apiResponce.Data, nil
 }
 
-func StartPolling(ctx context.Context) {
-	models.AppCtx = ctx
-	const postgresConnString = ""
-	database.InitDatabase(postgresConnString)
+// retryAfter разбирает Retry-After в секундах или в виде HTTP-даты.
+func retryAfter(h http.Header, now time.Time) time.Duration {
+	value := h.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
 
+// StartPolling опрашивает биржу до отмены ctx. База данных к этому моменту уже должна быть подключена.
+// Запуском и остановкой управляют Start/Stop/Pause/Resume.
+func StartPolling(ctx context.Context) {
 	log.Println("Terminus Engine: Online")
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
+	source := NewLiveSource(100 * time.Millisecond)
+	defer source.Stop()
 
-	for range ticker.C {
-		now := time.Now()
+	RunPolling(ctx, source)
+}
 
-		newTickers, err := FetchAllFuturesTickers()
+// RunPolling прогоняет снимки из источника через детектор, пока источник не вернет ошибку конца данных
+// или не будет отменен контекст.
+func RunPolling(ctx context.Context, source TickerSource) {
+	for {
+		newTickers, now, err := source.Next(ctx)
+		if errors.Is(err, io.EOF) || ctx.Err() != nil {
+			return
+		}
 		if err != nil {
+			// без свежих цен возврат не проверить, но таймауты должны срабатывать и при сбоях сети
+			StepTracking(now)
 			continue
 		}
 
-		TockenState.Mu.Lock()
-		for _, t := range newTickers {
-			state, exists := TockenState.TickerStates[t.Symbol]
+		recordSnapshot(newTickers, now)
+		ProcessTickers(newTickers, now)
+		StepTracking(now)
+	}
+}
+
+// ProcessTickers раскладывает снимок по шардам и ждет, пока воркеры шардов обновят состояние
+// своих символов и проверят их на сплеш. Все шарды видят один снимок конфигурации.
+func ProcessTickers(newTickers []models.SplashData, now time.Time) {
+	cfg := models.Config()
 
-			if !exists {
-				TockenState.TickerStates[t.Symbol] = models.TickerState{
-					WindowStartRef:   t,
-					LatestTickerData: t,
-					LastRefUpdate:    now,
+	var batches [models.StateShards][]models.SplashData
+	for _, t := range newTickers {
+		i := TockenState.ShardIndex(t.Symbol)
+		batches[i] = append(batches[i], t)
+	}
+
+	TockenState.Broadcast(func(sh *models.StateShard) {
+		if batch := batches[sh.Index]; len(batch) > 0 {
+			CheckPrices(cfg, sh, updateStates(cfg, sh, batch, now), now)
+		}
+	})
+}
+
+// updateStates обновляет историю цен символов шарда и возвращает тикеры, прошедшие фильтр символов и TickFilters.
+func updateStates(cfg *models.EngineConfig, sh *models.StateShard, tickers []models.SplashData, now time.Time) []models.SplashData {
+	history := cfg.MaxLookback()
+	filter := cfg.Filter
+	staleAfter := cfg.StaleAfterDuration()
+
+	allowed := tickers[:0]
+	for _, t := range tickers {
+		if !filter.Allows(t.Symbol, t.Volume24) {
+			// активный сплеш отфильтрованного символа доводим до конца, остальное его состояние не нужно
+			if state, ok := sh.States[t.Symbol]; ok {
+				if state.SplashTrigger {
+					if checkTick(cfg, &state, t) == "" {
+						state.LatestTickerData = t
+						state.LastUpdate = now
+					}
+					sh.States[t.Symbol] = state
+				} else {
+					delete(sh.States, t.Symbol)
 				}
-				continue
 			}
+			continue
+		}
 
-			if !state.SplashTrigger && now.Sub(state.LastRefUpdate) >= models.Window {
-				state.WindowStartRef = t
-				state.LastRefUpdate = now
-				state.LastTriggeredLevel = 0
+		state, exists := sh.States[t.Symbol]
+
+		if !exists {
+			state = models.TickerState{
+				History:     models.NewPriceRing(history),
+				HistoryFrom: now,
 			}
+		} else if !state.LastUpdate.IsZero() && now.Sub(state.LastUpdate) > staleAfter {
+			markGap(&state, t.Symbol, now)
+		}
 
-			state.LatestTickerData = t
-			TockenState.TickerStates[t.Symbol] = state
+		// плохой принт не попадает ни в историю, ни в детекцию, ни в проверку возврата
+		if reason := checkTick(cfg, &state, t); reason != "" {
+			if state.RejectedTicks == 0 {
+				log.Printf("BAD TICK: %s %s", t.Symbol, reason)
+			}
+			state.RejectedTicks++
+			sh.States[t.Symbol] = state
+			continue
 		}
-		TockenState.Mu.Unlock()
+		state.RejectedTicks = 0
+		allowed = append(allowed, t)
+
+		if exists {
+			updateAtr(&state, t, now)
+			state.History.EnsureWindow(history)
+		}
+		if t.LastPrice > 0 && t.FairPrice > 0 {
+			state.History.Push(t, now)
+			state.LastUpdate = now
+		}
+
+		state.LatestTickerData = t
+		sh.States[t.Symbol] = state
+	}
+	return allowed
+}
+
+// markGap отмечает разрыв ленты по символу: цены до разрыва не годятся как референс для свежих,
+// поэтому история и текущий минутный бар сбрасываются. Сглаженные ATR и волатильность сохраняются.
+func markGap(state *models.TickerState, symbol string, now time.Time) {
+	log.Printf("DATA GAP: %s not updated for %s", symbol, now.Sub(state.LastUpdate).Round(time.Second))
+	state.History.Reset()
+	state.HistoryFrom = now
+	state.GapAt = now
+	state.BarStart = time.Time{}
+	state.PrevClose = 0
+	state.RecentPrints = state.RecentPrints[:0]
+}
 
-		CheckPrices(newTickers, now)
+// nearGap сообщает, что сплеш символа задет разрывом данных: разрыв случился в пределах lookback
+// до срабатывания, во время слежения или данные прямо сейчас устарели.
+func nearGap(state models.TickerState, triggerTime time.Time, lookback, staleAfter time.Duration, now time.Time) bool {
+	if !state.GapAt.IsZero() && state.GapAt.After(triggerTime.Add(-lookback)) {
+		return true
 	}
+	return !state.LastUpdate.IsZero() && now.Sub(state.LastUpdate) > staleAfter
 }
 
-func CheckPrices(newTickers []models.SplashData, now time.Time) {
+// CheckPrices ищет сплеши среди символов шарда sh по снимку конфигурации cfg, общему для всего прохода.
+// Вызывается только из воркера шарда.
+func CheckPrices(cfg *models.EngineConfig, sh *models.StateShard, newTickers []models.SplashData, now time.Time) {
 	for _, ticker := range newTickers {
-		TockenState.Mu.Lock()
-		state, ok := TockenState.TickerStates[ticker.Symbol]
+		state, ok := sh.States[ticker.Symbol]
 		if !ok {
-			TockenState.Mu.Unlock()
 			continue
 		}
 
-		ref := state.WindowStartRef
-		if ref.LastPrice <= 0 || ticker.LastPrice <= 0 {
+		// закрепленный сплеш не перекрывается прогрессией и не дает новых сигналов до снятия
+		if ticker.LastPrice <= 0 || ticker.FairPrice <= 0 || (state.SplashTrigger && state.ForcedPin) {
 			state.LatestTickerData = ticker
-			TockenState.TickerStates[ticker.Symbol] = state
-			TockenState.Mu.Unlock()
+			sh.States[ticker.Symbol] = state
 			continue
 		}
 
-		lastChange := math.Abs(ticker.LastPrice-ref.LastPrice) / ref.LastPrice
-		fairChange := math.Abs(ticker.FairPrice-ref.FairPrice) / ref.FairPrice
-		maxChange := math.Max(lastChange, fairChange)
+		tiers := cfg.TiersFor(ticker.Symbol, ticker.Volume24)
+		// на время активного сплеша множитель фиксируется, чтобы прогрессия шла по тем же уровням
+		scale := levelScale(state, cfg.Adaptive)
+		if state.SplashTrigger && state.LevelScale > 0 {
+			scale = state.LevelScale
+		}
 
-		tier, isTriggered := GetNextSplash(maxChange, state.LastTriggeredLevel)
+		var (
+			tier                   models.SplashTier
+			ref                    models.RefPoint
+			direction              string
+			lastChange, fairChange float64
+			isTriggered            bool
+		)
+		for _, lb := range cfg.TierLookbacks(tiers) {
+			// Рост меряем от скользящего минимума, падение — от максимума.
+			// Данные до HistoryFrom уже отработаны закрытым сплешем и не учитываются.
+			from := now.Add(-lb)
+			if state.HistoryFrom.After(from) {
+				from = state.HistoryFrom
+			}
+			low, high, ok := state.History.Extremes(from)
+			if !ok {
+				continue
+			}
 
-		if isTriggered {
-			if !state.SplashTrigger || tier.Level > (state.LastTriggeredLevel*100) {
-				TockenState.Mu.Unlock()
-				SplashHandle(ticker, tier, lastChange, fairChange, ref, now, state)
+			for _, side := range []struct {
+				ref       models.RefPoint
+				direction string
+			}{{low, models.DirectionUp}, {high, models.DirectionDown}} {
+				if state.SplashTrigger && side.direction != state.SplashDirection {
+					continue
+				}
+				refData := side.ref.Data
+				if refData.LastPrice <= 0 || refData.FairPrice <= 0 {
+					continue
+				}
+
+				lc := (ticker.LastPrice - refData.LastPrice) / refData.LastPrice
+				fc := (ticker.FairPrice - refData.FairPrice) / refData.FairPrice
+				change := math.Max(lc, fc)
+				if side.direction == models.DirectionDown {
+					change = math.Min(lc, fc)
+				}
+
+				candidate, ok := GetNextSplash(cfg, tiers, change, state.LastTriggeredLevel, lb, scale)
+				if ok && (!isTriggered || candidate.Level > tier.Level) {
+					tier, ref, direction, isTriggered = candidate, side.ref, side.direction, true
+					lastChange, fairChange = math.Abs(lc), math.Abs(fc)
+				}
+			}
+		}
+
+		if isTriggered && (!state.SplashTrigger || tier.Level > (state.LastTriggeredLevel*100)) {
+			// с ConfirmUpdates > 1 сплеш срабатывает, только продержавшись столько снимков подряд
+			if confirmSplash(cfg, &state, direction) {
+				SplashHandle(cfg, sh, ticker, tier, direction, scale, lastChange, fairChange, ref.Data, ref.Since, state)
 				continue
 			}
+		} else {
+			state.PendingDir, state.PendingHits = "", 0
 		}
 
 		state.LatestTickerData = ticker
-		TockenState.TickerStates[ticker.Symbol] = state
-		TockenState.Mu.Unlock()
+		sh.States[ticker.Symbol] = state
 	}
 }
 
-func SplashHandle(ticker models.SplashData, tier models.SplashTier, lpCh, fpCh float64, ref models.SplashData, refTime time.Time, state models.TickerState) {
-	now := time.Now()
+func SplashHandle(cfg *models.EngineConfig, sh *models.StateShard, ticker models.SplashData, tier models.SplashTier, direction string, scale float64, lpCh, fpCh float64, ref models.SplashData, refTime time.Time, state models.TickerState) {
+	now := EngineClock.Now()
 	basisGap := (math.Abs(ticker.LastPrice-ticker.FairPrice) / ticker.FairPrice) * 100
 
 	speed := now.Sub(refTime).Seconds()
@@ -163,36 +347,37 @@ func SplashHandle(ticker models.SplashData, tier models.SplashTier, lpCh, fpCh f
 		speed = now.Sub(state.TriggerTime).Seconds()
 	}
 
-	direction := "UP"
-	if ticker.LastPrice < ref.LastPrice {
-		direction = "DOWN"
-	}
-
 	targetLevelInt := int(math.Round(tier.Level))
+	toleranceMode, tolerance := returnTolerance(tier, math.Max(lpCh, fpCh), state)
 
 	if state.SplashTrigger {
 		lastLevelInt := int(math.Round(state.LastTriggeredLevel * 100))
 		if direction == state.SplashDirection && targetLevelInt > lastLevelInt {
-			total, wins, _ := database.GetContextStats(direction, targetLevelInt, ticker.Volume24, basisGap, tier.Window)
+			total, wins, _ := Store.GetContextStats(direction, targetLevelInt, ticker.Volume24, basisGap, tier.Window, toleranceMode, now)
 			prob := -1.0
 			if total >= 3 {
 				prob = math.Round((float64(wins) / float64(total)) * 100)
 			}
 
-			database.UpdateSplashLevel(state.SplashRecordID, targetLevelInt, ticker.LastPrice, ticker.FairPrice, ticker.Volume24, prob, tier.Window)
+			// дойдя до закрепленного тира, сплеш закрепляется и больше не уходит по таймауту
+			state.ForcedPin = state.ForcedPin || tier.IsForcedPin
+			err := Store.UpdateSplashLevel(state.SplashRecordID, targetLevelInt, ticker.LastPrice, ticker.FairPrice, ticker.Volume24, prob, tier.Window, toleranceMode, tolerance, state.ForcedPin)
+			if err != nil {
+				log.Printf("Error updating splash level for record ID %d: %v", state.SplashRecordID, err)
+			}
 
 			state.LastTriggeredLevel = float64(targetLevelInt) / 100.0
 			state.CurrentTimeWindow = tier.Window
-			TockenState.Mu.Lock()
-			TockenState.TickerStates[ticker.Symbol] = state
-			TockenState.Mu.Unlock()
+			state.ReturnTolerance = tolerance
+			sh.States[ticker.Symbol] = state
 
-			sendWailsEvent(ticker, direction, tier, prob, basisGap, speed, ref, "ACTIVE")
+			dataGap := nearGap(state, state.TriggerTime, cfg.TierLookback(tier), cfg.StaleAfterDuration(), now)
+			sendWailsEvent(cfg, ticker, direction, tier, prob, basisGap, speed, tolerance, scale, dataGap, ref, models.StatusActive)
 		}
 		return
 	}
 
-	total, wins, _ := database.GetContextStats(direction, targetLevelInt, ticker.Volume24, basisGap, tier.Window)
+	total, wins, _ := Store.GetContextStats(direction, targetLevelInt, ticker.Volume24, basisGap, tier.Window, toleranceMode, now)
 	prob := -1.0
 	if total >= 3 {
 		prob = math.Round((float64(wins) / float64(total)) * 100)
@@ -210,10 +395,17 @@ func SplashHandle(ticker models.SplashData, tier models.SplashTier, lpCh, fpCh f
 		Volume24h:        ticker.Volume24,
 		LongProbability:  prob,
 		TimeWindow:       tier.Window,
+		ToleranceMode:    toleranceMode,
+		Tolerance:        tolerance,
+		LevelScale:       scale,
+		ForcedPin:        tier.IsForcedPin,
+		ConfigVersion:    cfg.Version,
+		DataGap:          nearGap(state, now, cfg.TierLookback(tier), cfg.StaleAfterDuration(), now),
 	}
 
-	recordID, err := database.SaveSplashRecord(record, basisGap, speed)
+	recordID, err := insertSplashRecord(record, basisGap, speed)
 	if err != nil {
+		log.Printf("Error saving splash record for %s %s %d%%: %v", ticker.Symbol, direction, targetLevelInt, err)
 		return
 	}
 
@@ -222,26 +414,31 @@ func SplashHandle(ticker models.SplashData, tier models.SplashTier, lpCh, fpCh f
 	state.SplashTrigger = true
 	state.TriggerTime = now
 	state.SplashDirection = direction
+	state.ReturnTolerance = tolerance
+	state.LevelScale = scale
+	state.ForcedPin = tier.IsForcedPin
+
+	sh.States[ticker.Symbol] = state
 
-	TockenState.Mu.Lock()
-	TockenState.TickerStates[ticker.Symbol] = state
-	TockenState.Mu.Unlock()
+	sendWailsEvent(cfg, ticker, direction, tier, prob, basisGap, speed, tolerance, scale, record.DataGap, ref, models.StatusActive)
 
-	sendWailsEvent(ticker, direction, tier, prob, basisGap, speed, ref, "ACTIVE")
+	if tier.PaperTrade {
+		openPaperTrade(cfg, recordID, ticker, direction, targetLevelInt, now)
+	}
 
-	go TrackReturnBack(recordID, ticker.Symbol, ref.LastPrice, ref.FairPrice, now, direction, tier.Window)
+	startReturnTracking(sh, recordID, ticker.Symbol, ref.LastPrice, ref.FairPrice, now, direction, tier.Window)
 }
 
-func sendWailsEvent(ticker models.SplashData, dir string, tier models.SplashTier, prob, gap, spd float64, prev models.SplashData, status string) {
-	if models.AppCtx == nil {
-		return
-	}
-	runtime.EventsEmit(models.AppCtx, "splash:new", map[string]interface{}{
+func sendWailsEvent(cfg *models.EngineConfig, ticker models.SplashData, dir string, tier models.SplashTier, prob, gap, spd, tolerance, scale float64, dataGap bool, prev models.SplashData, status string) {
+	emitSplashEvent(cfg, map[string]interface{}{
 		"symbol":       ticker.Symbol,
 		"exchange":     "MEXC",
 		"direction":    dir,
 		"level":        int(tier.Level),
 		"activeWindow": tier.Window,
+		"isForcedPin":  tier.IsForcedPin,
+		"dataGap":      dataGap,
+		"lookback":     cfg.TierLookback(tier).Minutes(),
 		"prob":         math.Round(prob),
 		"refLast":      fmt.Sprintf("%.6f", prev.LastPrice),
 		"refFair":      fmt.Sprintf("%.6f", prev.FairPrice),
@@ -249,8 +446,18 @@ func sendWailsEvent(ticker models.SplashData, dir string, tier models.SplashTier
 		"fairPrice":    fmt.Sprintf("%.6f", ticker.FairPrice),
 		"gap":          fmt.Sprintf("%.2f", gap),
 		"speed":        fmt.Sprintf("%.1f", spd),
+		"tolerance":    fmt.Sprintf("%.2f", tolerance*100),
+		"scale":        fmt.Sprintf("%.2f", scale),
 		"volume":       ticker.Volume24,
-		"timestamp":    time.Now().Format("15:04:05"),
+		"timestamp":    EngineClock.Now().Format("15:04:05"),
 		"status":       status,
 	})
 }
+
+// emitSplashEvent отправляет событие в UI и во все вебхуки из снимка конфигурации cfg.
+func emitSplashEvent(cfg *models.EngineConfig, payload map[string]interface{}) {
+	if models.AppCtx != nil {
+		runtime.EventsEmit(models.AppCtx, "splash:new", payload)
+	}
+	notifier.Notify(cfg.Webhooks, payload)
+}
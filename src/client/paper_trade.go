@@ -0,0 +1,67 @@
+package client
+
+import (
+	"fmt"
+	"log"
+	"splash-trading-bot/lib/models"
+	"splash-trading-bot/src/paper"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+func openPaperTrade(cfg *models.EngineConfig, recordID int64, ticker models.SplashData, direction string, level int, now time.Time) {
+	trade, ok := paper.Open(recordID, ticker.Symbol, direction, level, ticker.LastPrice, now, cfg.Paper)
+	if !ok {
+		return
+	}
+	log.Printf("PAPER OPEN: %s %s @ %.6f (stop %.6f)", trade.Symbol, trade.Side, trade.EntryPrice, trade.StopPrice)
+	emitPaperEvent(trade, "OPEN")
+}
+
+// markPaperTrade проверяет стоп-лосс открытой позиции по последней цене.
+func markPaperTrade(recordID int64, price float64, now time.Time) {
+	if trade, closed := paper.Mark(recordID, price, now); closed {
+		savePaperTrade(trade)
+	}
+}
+
+func settlePaperTrade(recordID int64, reason string, price float64, now time.Time) {
+	if trade, closed := paper.Close(recordID, reason, price, now); closed {
+		savePaperTrade(trade)
+	}
+}
+
+// savePaperTrade ставит закрытую сделку в очередь записи; событие в UI уходит после записи, уже с id.
+func savePaperTrade(trade models.PaperTrade) {
+	log.Printf("PAPER CLOSE: %s %s %s | PnL %.2f (%.2f%%)", trade.Symbol, trade.Side, trade.ExitReason, trade.PnL, trade.PnLPercent)
+
+	enqueueWrite(func() {
+		id, err := Store.SavePaperTrade(trade)
+		if err != nil {
+			log.Printf("Error saving paper trade for record ID %d: %v", trade.RecordID, err)
+		}
+		trade.ID = id
+
+		emitPaperEvent(trade, "CLOSED")
+	})
+}
+
+func emitPaperEvent(t models.PaperTrade, status string) {
+	if models.AppCtx == nil {
+		return
+	}
+	runtime.EventsEmit(models.AppCtx, "paper:trade", map[string]interface{}{
+		"recordId":   t.RecordID,
+		"symbol":     t.Symbol,
+		"side":       t.Side,
+		"level":      t.Level,
+		"status":     status,
+		"entryPrice": fmt.Sprintf("%.6f", t.EntryPrice),
+		"exitPrice":  fmt.Sprintf("%.6f", t.ExitPrice),
+		"stopPrice":  fmt.Sprintf("%.6f", t.StopPrice),
+		"exitReason": t.ExitReason,
+		"pnl":        fmt.Sprintf("%.2f", t.PnL),
+		"pnlPercent": fmt.Sprintf("%.2f", t.PnLPercent),
+	})
+}
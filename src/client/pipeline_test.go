@@ -0,0 +1,239 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"splash-trading-bot/lib/clock"
+	"splash-trading-bot/lib/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	models.AppCtx = nil
+	os.Exit(m.Run())
+}
+
+var testStart = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// testStore — хранилище в памяти, запоминающее скорость срабатывания каждого сплеша.
+type testStore struct {
+	mu      sync.Mutex
+	records map[int64]models.SplashRecord
+	speeds  map[int64]float64
+}
+
+func (s *testStore) GetContextStats(string, int, float64, float64, int, string, time.Time) (int, int, error) {
+	return 0, 0, nil
+}
+
+// SaveSplashRecord, как и уникальный индекс в базе, не допускает двух активных записей одного символа и уровня.
+func (s *testStore) SaveSplashRecord(r models.SplashRecord, basisGap float64, speedSeconds float64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.records {
+		active := existing.Status == "" || existing.Status == models.StatusActive
+		if active && existing.Symbol == r.Symbol && existing.TriggerLevel == r.TriggerLevel {
+			return 0, fmt.Errorf("failed to insert splash record: active splash already exists")
+		}
+	}
+	id := int64(len(s.records) + 1)
+	r.ID = int(id)
+	s.records[id] = r
+	s.speeds[id] = speedSeconds
+	return id, nil
+}
+
+func (s *testStore) UpdateSplashLevel(id int64, level int, lastPrice float64, fairPrice float64, volume24 float64, probWin float64, window int, toleranceMode string, tolerance float64, forcedPin bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.records[id]
+	r.TriggerLevel = level
+	r.TimeWindow = window
+	r.ForcedPin = r.ForcedPin || forcedPin
+	s.records[id] = r
+	return nil
+}
+
+func (s *testStore) GetActiveSplashRecords() ([]models.SplashRecord, error) {
+	return nil, nil
+}
+
+func (s *testStore) CloseSplashRecord(id int64, status string, returnTime time.Duration, maxDeviation float64, dataGap bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.records[id]
+	r.Returned = status == models.StatusReturned
+	r.Status = status
+	r.ReturnTime = returnTime
+	r.MaxDeviation = maxDeviation
+	r.DataGap = r.DataGap || dataGap
+	s.records[id] = r
+	return nil
+}
+
+func (s *testStore) SavePaperTrade(models.PaperTrade) (int64, error) {
+	return 0, nil
+}
+
+func (s *testStore) SaveConfigVersion(string, models.EngineConfig) (int64, error) {
+	return 1, nil
+}
+
+func (s *testStore) record(t testing.TB, id int64) models.SplashRecord {
+	t.Helper()
+	FlushRecords()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[id]
+	if !ok {
+		t.Fatalf("record with ID %d not found", id)
+	}
+	return r
+}
+
+func (s *testStore) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.records)
+}
+
+// active возвращает число сплешей, которые еще не закрыты ни возвратом, ни таймаутом.
+func (s *testStore) active() int {
+	FlushRecords()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, r := range s.records {
+		if r.Status == "" || r.Status == models.StatusActive {
+			n++
+		}
+	}
+	return n
+}
+
+// testEngine подменяет глобальное состояние движка: часы, хранилище, шарды и слежение.
+type testEngine struct {
+	clock *clock.Fake
+	store *testStore
+}
+
+func newTestEngine(t testing.TB, tiers ...models.SplashTier) *testEngine {
+	t.Helper()
+	e := &testEngine{
+		clock: clock.NewFake(testStart),
+		store: &testStore{records: make(map[int64]models.SplashRecord), speeds: make(map[int64]float64)},
+	}
+
+	prevClock, prevStore, prevState, prevConfig := EngineClock, Store, TockenState, *models.Config()
+	EngineClock, Store, TockenState = e.clock, e.store, models.NewSharedState()
+	tracks = [models.StateShards][]*returnTrack{}
+	t.Cleanup(func() {
+		FlushRecords()
+		TockenState.Close()
+		EngineClock, Store, TockenState = prevClock, prevStore, prevState
+		tracks = [models.StateShards][]*returnTrack{}
+		models.SetConfig(prevConfig)
+	})
+
+	cfg := models.DefaultConfig()
+	cfg.Tiers = tiers
+	cfg.Overrides = nil
+	cfg.Filter = models.SymbolFilter{}
+	cfg.Adaptive.Enabled = false
+	if errs := ApplyConfig(cfg); len(errs) > 0 {
+		t.Fatalf("invalid test config: %v", errs)
+	}
+	return e
+}
+
+// tick продвигает часы на d и прогоняет один снимок с ценой price, как цикл опроса.
+func (e *testEngine) tick(d time.Duration, price float64) {
+	e.clock.Advance(d)
+	now := e.clock.Now()
+	ProcessTickers([]models.SplashData{{Symbol: "TEST_USDT", LastPrice: price, FairPrice: price, Volume24: 1e6}}, now)
+	StepTracking(now)
+}
+
+func TestSplashTimesOutAfterTierWindow(t *testing.T) {
+	e := newTestEngine(t, models.SplashTier{Level: 3, Window: 1})
+	for i := 0; i < 5; i++ {
+		e.tick(time.Second, 100)
+	}
+	e.tick(time.Second, 104)
+	triggered := e.clock.Now()
+	if e.store.count() != 1 {
+		t.Fatalf("records = %d, want 1", e.store.count())
+	}
+
+	for e.clock.Since(triggered) < time.Minute {
+		e.tick(time.Second, 104)
+	}
+	if r := e.store.record(t, 1); r.Status != "" && r.Status != models.StatusActive {
+		t.Fatalf("status = %s at exactly the window, want still active", r.Status)
+	}
+
+	e.tick(time.Second, 104)
+	r := e.store.record(t, 1)
+	if r.Status != models.StatusTimeout {
+		t.Fatalf("status = %q, want %s", r.Status, models.StatusTimeout)
+	}
+	if want := time.Minute + time.Second; r.ReturnTime != want {
+		t.Fatalf("return time = %s, want %s", r.ReturnTime, want)
+	}
+}
+
+func TestSplashReturnTime(t *testing.T) {
+	e := newTestEngine(t, models.SplashTier{Level: 3, Window: 5})
+	for i := 0; i < 5; i++ {
+		e.tick(time.Second, 100)
+	}
+	e.tick(time.Second, 104)
+	for i := 0; i < 4; i++ {
+		e.tick(time.Second, 104)
+	}
+	e.tick(1500*time.Millisecond, 100.2)
+
+	r := e.store.record(t, 1)
+	if r.Status != models.StatusReturned || !r.Returned {
+		t.Fatalf("status = %q returned = %v, want %s", r.Status, r.Returned, models.StatusReturned)
+	}
+	if want := 5500 * time.Millisecond; r.ReturnTime != want {
+		t.Fatalf("return time = %s, want %s", r.ReturnTime, want)
+	}
+	if want := 0.04; r.MaxDeviation < want-1e-9 || r.MaxDeviation > want+1e-9 {
+		t.Fatalf("max deviation = %v, want %v", r.MaxDeviation, want)
+	}
+}
+
+func TestSplashSpeedFromReference(t *testing.T) {
+	e := newTestEngine(t, models.SplashTier{Level: 3, Window: 5})
+	// минимум окна — 100 через 2 секунды после старта, дальше цена растет к скачку
+	e.tick(time.Second, 101)
+	e.tick(time.Second, 100)
+	for _, p := range []float64{101, 101.5, 102, 102.5, 102.8} {
+		e.tick(time.Second, p)
+	}
+	if e.store.count() != 0 {
+		t.Fatalf("splash fired below the tier level")
+	}
+	e.tick(3*time.Second, 103.5)
+
+	if e.store.count() != 1 {
+		t.Fatalf("records = %d, want 1", e.store.count())
+	}
+	r := e.store.record(t, 1)
+	if r.RefLastPrice != 100 {
+		t.Fatalf("reference = %v, want the window low 100", r.RefLastPrice)
+	}
+	if want := 8.0; e.store.speeds[1] != want {
+		t.Fatalf("speed = %vs, want %vs from the low to the trigger", e.store.speeds[1], want)
+	}
+	if !r.TriggerTime.Equal(e.clock.Now()) {
+		t.Fatalf("trigger time = %s, want %s", r.TriggerTime, e.clock.Now())
+	}
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"log"
+	"splash-trading-bot/lib/models"
+	"sync"
+	"time"
+)
+
+// RecordQueueSize — сколько записей может ждать хранилища, прежде чем воркеры шардов начнут блокироваться.
+const RecordQueueSize = 1024
+
+// recordWrites — очередь записей в хранилище. Единственный писатель выполняет их в порядке постановки,
+// поэтому новый сплеш попадает в базу только после закрытия всех исходов, поставленных раньше.
+var (
+	recordWrites = make(chan func(), RecordQueueSize)
+	writerOnce   sync.Once
+)
+
+func writeRecords() {
+	for write := range recordWrites {
+		write()
+	}
+}
+
+func enqueueWrite(write func()) {
+	writerOnce.Do(func() { go writeRecords() })
+	recordWrites <- write
+}
+
+// SaveReturnBackRecord ставит исход сплеша в очередь записи и не ждет базу, поэтому
+// безопасен внутри воркеров шардов. status — один из models.Status*. dataGap дополняет
+// флаг разрыва данных, выставленный при срабатывании.
+func SaveReturnBackRecord(recordID int64, status string, returnTime time.Duration, maxDeviation float64, dataGap bool) {
+	enqueueWrite(func() {
+		if err := Store.CloseSplashRecord(recordID, status, returnTime, maxDeviation, dataGap); err != nil {
+			log.Printf("Error updating splash record ID %d: %v", recordID, err)
+		}
+	})
+}
+
+// insertSplashRecord записывает новый сплеш через очередь и ждет его id. В обход очереди вставка
+// могла бы обогнать еще не записанное закрытие прошлого сплеша того же символа и уровня
+// и упереться в уникальный индекс активных записей.
+func insertSplashRecord(r models.SplashRecord, basisGap float64, speedSeconds float64) (int64, error) {
+	type result struct {
+		id  int64
+		err error
+	}
+	done := make(chan result, 1)
+	enqueueWrite(func() {
+		id, err := Store.SaveSplashRecord(r, basisGap, speedSeconds)
+		done <- result{id, err}
+	})
+	res := <-done
+	return res.id, res.err
+}
+
+// FlushRecords ждет, пока будут выполнены все записи, поставленные до вызова.
+func FlushRecords() {
+	done := make(chan struct{})
+	enqueueWrite(func() { close(done) })
+	<-done
+}
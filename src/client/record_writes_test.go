@@ -0,0 +1,38 @@
+package client
+
+import (
+	"splash-trading-bot/lib/models"
+	"testing"
+	"time"
+)
+
+func TestInsertWaitsForQueuedClose(t *testing.T) {
+	e := newTestEngine(t, models.SplashTier{Level: 3, Window: 5})
+	record := models.SplashRecord{Symbol: "TEST_USDT", Direction: models.DirectionUp, TriggerLevel: 3, TriggerTime: testStart}
+	first, err := insertSplashRecord(record, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// писатель занят, закрытие первой записи еще в очереди
+	gate := make(chan struct{})
+	enqueueWrite(func() { <-gate })
+	SaveReturnBackRecord(first, models.StatusReturned, time.Second, 0, false)
+
+	inserted := make(chan error, 1)
+	go func() {
+		_, err := insertSplashRecord(record, 0, 0)
+		inserted <- err
+	}()
+	close(gate)
+
+	if err := <-inserted; err != nil {
+		t.Fatalf("second insert: %v, want it written after the queued close", err)
+	}
+	if r := e.store.record(t, first); r.Status != models.StatusReturned {
+		t.Fatalf("first record status = %q, want %s", r.Status, models.StatusReturned)
+	}
+	if e.store.count() != 2 {
+		t.Fatalf("records = %d, want 2", e.store.count())
+	}
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"fmt"
+	"log"
+	"splash-trading-bot/lib/models"
+	"time"
+)
+
+// RecoverActiveSplashes поднимает сплеши, оставшиеся ACTIVE после прошлого запуска. Сплеши, чье окно
+// еще не истекло (и все закрепленные), снова ставятся на слежение; остальные закрываются как TIMEOUT
+// с временем возврата, равным окну. Все поднятые записи получают флаг разрыва данных. Вызывается до старта опроса.
+func RecoverActiveSplashes(now time.Time) (resumed, expired int, err error) {
+	records, err := Store.GetActiveSplashRecords()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load active splashes: %w", err)
+	}
+
+	history := models.Config().MaxLookback()
+	for _, r := range records {
+		window := time.Duration(r.TimeWindow) * time.Minute
+		if !r.ForcedPin && now.Sub(r.TriggerTime) > window {
+			log.Printf("RECOVERY TIMEOUT: %s (record %d) expired while offline", r.Symbol, r.ID)
+			SaveReturnBackRecord(int64(r.ID), models.StatusTimeout, window, r.MaxDeviation, true)
+			expired++
+			continue
+		}
+
+		restored := false
+		TockenState.Do(r.Symbol, func(sh *models.StateShard) {
+			// на символ может остаться несколько записей; записи идут от новых к старым, поднимаем только первую
+			if state, ok := sh.States[r.Symbol]; ok && state.SplashTrigger {
+				return
+			}
+			sh.States[r.Symbol] = models.TickerState{
+				History:            models.NewPriceRing(history),
+				HistoryFrom:        now,
+				SplashTrigger:      true,
+				TriggerTime:        r.TriggerTime,
+				LastTriggeredLevel: float64(r.TriggerLevel) / 100.0,
+				CurrentTimeWindow:  r.TimeWindow,
+				SplashDirection:    r.Direction,
+				SplashRecordID:     int64(r.ID),
+				ReturnTolerance:    r.Tolerance,
+				LevelScale:         r.LevelScale,
+				ForcedPin:          r.ForcedPin,
+				// пока приложение было закрыто, слежения не было
+				GapAt: now,
+			}
+			startReturnTracking(sh, int64(r.ID), r.Symbol, r.RefLastPrice, r.RefFairPrice, r.TriggerTime, r.Direction, r.TimeWindow)
+			restored = true
+		})
+
+		if !restored {
+			log.Printf("RECOVERY TIMEOUT: %s (record %d) superseded by a newer splash", r.Symbol, r.ID)
+			SaveReturnBackRecord(int64(r.ID), models.StatusTimeout, now.Sub(r.TriggerTime), r.MaxDeviation, true)
+			expired++
+			continue
+		}
+		log.Printf("RECOVERY RESUMED: %s %s %d%% (record %d)", r.Symbol, r.Direction, r.TriggerLevel, r.ID)
+		resumed++
+	}
+	// до старта опроса просроченные записи уже должны быть закрыты
+	FlushRecords()
+	return resumed, expired, nil
+}
@@ -4,127 +4,181 @@ import (
 	"fmt"
 	"log"
 	"math"
-	"splash-trading-bot/database"
 	"splash-trading-bot/lib/models"
+	"splash-trading-bot/src/paper"
 	"time"
-
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-func TrackReturnBack(
-	recordID int64,
-	symbol string,
-	refLastPrice float64,
-	refFairPrice float64,
-	triggerTime time.Time,
-	direction string,
-	userWindowMin int,
-) {
-	ticker := time.NewTicker(50 * time.Millisecond)
-	defer ticker.Stop()
-	warmup := 0
-	maxDeviation := 0.0
-
-	for range ticker.C {
-		if warmup < 2 {
-			warmup++
-			continue
-		}
-		TockenState.Mu.Lock()
-		state, ok := TockenState.TickerStates[symbol]
-		TockenState.Mu.Unlock()
-
-		if !ok || !state.SplashTrigger || state.SplashRecordID != recordID {
-			return
-		}
+// tracks — сплеши на слежении, разложенные по шардам состояния. tracks[i] читает и меняет
+// только воркер шарда i, поэтому список живет без блокировок.
+var tracks [models.StateShards][]*returnTrack
+
+type returnTrack struct {
+	recordID      int64
+	symbol        string
+	refLastPrice  float64
+	refFairPrice  float64
+	triggerTime   time.Time
+	direction     string
+	userWindowMin int
+
+	warmup       int
+	maxDeviation float64
+}
 
-		currentTimeWindow := state.CurrentTimeWindow
-		if currentTimeWindow == 0 {
-			currentTimeWindow = userWindowMin
-		}
+// startReturnTracking ставит сплеш на слежение за возвратом в шарде символа.
+// Проверка начнется со следующего StepTracking.
+func startReturnTracking(sh *models.StateShard, recordID int64, symbol string, refLastPrice, refFairPrice float64, triggerTime time.Time, direction string, userWindowMin int) {
+	tracks[sh.Index] = append(tracks[sh.Index], &returnTrack{
+		recordID:      recordID,
+		symbol:        symbol,
+		refLastPrice:  refLastPrice,
+		refFairPrice:  refFairPrice,
+		triggerTime:   triggerTime,
+		direction:     direction,
+		userWindowMin: userWindowMin,
+	})
+}
 
-		currentLevel := state.LastTriggeredLevel
-		maxReturnWindow := time.Duration(currentTimeWindow) * time.Minute
-		tolerance := dynamicTolerance(currentLevel)
-
-		timeSinceTrigger := time.Since(triggerTime)
-		if timeSinceTrigger > maxReturnWindow {
-			log.Printf("TIMEOUT: %s exceeded user window of %d min", symbol, userWindowMin)
-			if models.AppCtx != nil {
-				runtime.EventsEmit(models.AppCtx, "splash:new", map[string]interface{}{
-					"symbol": symbol,
-					"status": "TIMEOUT",
-				})
+// StepTracking выполняет один шаг проверки всех активных сплешей: каждый воркер проверяет сплеши своего шарда.
+// Все шарды видят один снимок конфигурации.
+func StepTracking(now time.Time) {
+	cfg := models.Config()
+	TockenState.Broadcast(func(sh *models.StateShard) {
+		list := tracks[sh.Index]
+		active := list[:0]
+		for _, track := range list {
+			if !track.step(cfg, sh, now) {
+				active = append(active, track)
 			}
-			SaveReturnBackRecord(recordID, false, timeSinceTrigger, maxDeviation)
-			resetTickerState(symbol)
-			return
-		}
-		currentData := state.LatestTickerData
-		if currentData.LastPrice == 0 || currentData.FairPrice == 0 {
-			continue
 		}
+		clear(list[len(active):])
+		tracks[sh.Index] = active
+	})
+}
 
-		lastPriceChangeToRef := math.Abs(currentData.LastPrice-refLastPrice) / refLastPrice
-		fairPriceChangeToRef := math.Abs(currentData.FairPrice-refFairPrice) / refFairPrice
-		currentDeviation := math.Max(lastPriceChangeToRef, fairPriceChangeToRef)
+// step проверяет возврат цены к референсу и таймаут окна. Возвращает true, когда слежение завершено.
+func (t *returnTrack) step(cfg *models.EngineConfig, sh *models.StateShard, now time.Time) bool {
+	if t.warmup < 2 {
+		t.warmup++
+		return false
+	}
+	state, ok := sh.States[t.symbol]
 
-		if currentDeviation > maxDeviation {
-			maxDeviation = currentDeviation
-		}
+	if !ok || !state.SplashTrigger || state.SplashRecordID != t.recordID {
+		settlePaperTrade(t.recordID, paper.ExitCancelled, state.LatestTickerData.LastPrice, now)
+		return true
+	}
 
-		if currentDeviation <= tolerance {
-			timeToReturn := time.Since(triggerTime)
-			log.Printf("PRICE RETURNED: %s | LEVEL: %.0f%%", symbol, currentLevel*100)
-
-			if models.AppCtx != nil {
-				runtime.EventsEmit(models.AppCtx, "splash:new", map[string]interface{}{
-					"symbol":     symbol,
-					"status":     "RETURNED",
-					"returnTime": fmt.Sprintf("%.2f", timeToReturn.Seconds()),
-					"lastPrice":  fmt.Sprintf("%.6f", currentData.LastPrice),
-					"fairPrice":  fmt.Sprintf("%.6f", currentData.FairPrice),
-				})
-			}
-			SaveReturnBackRecord(recordID, true, timeToReturn, maxDeviation)
-			resetTickerState(symbol)
-			return
-		}
+	staleAfter := cfg.StaleAfterDuration()
+	stale := !state.LastUpdate.IsZero() && now.Sub(state.LastUpdate) > staleAfter
+	dataGap := nearGap(state, t.triggerTime, 0, staleAfter, now)
+
+	if state.Dismissed {
+		log.Printf("DISMISSED: %s", t.symbol)
+		settlePaperTrade(t.recordID, paper.ExitCancelled, state.LatestTickerData.LastPrice, now)
+		emitSplashEvent(cfg, map[string]interface{}{
+			"symbol":      t.symbol,
+			"status":      models.StatusDismissed,
+			"isForcedPin": state.ForcedPin,
+		})
+		SaveReturnBackRecord(t.recordID, models.StatusDismissed, now.Sub(t.triggerTime), t.maxDeviation, dataGap)
+		resetTickerState(sh, t.symbol)
+		return true
+	}
 
+	currentTimeWindow := state.CurrentTimeWindow
+	if currentTimeWindow == 0 {
+		currentTimeWindow = t.userWindowMin
 	}
-}
 
-func resetTickerState(symbol string) {
-	TockenState.Mu.Lock()
-	defer TockenState.Mu.Unlock()
+	currentLevel := state.LastTriggeredLevel
+	maxReturnWindow := time.Duration(currentTimeWindow) * time.Minute
+	tolerance := state.ReturnTolerance
+	if tolerance <= 0 {
+		tolerance = dynamicTolerance(currentLevel)
+	}
+
+	// закрепленный сплеш не уходит по таймауту и ждет возврата или ручного снятия
+	timeSinceTrigger := now.Sub(t.triggerTime)
+	if !state.ForcedPin && timeSinceTrigger > maxReturnWindow {
+		log.Printf("TIMEOUT: %s exceeded user window of %d min", t.symbol, t.userWindowMin)
+		settlePaperTrade(t.recordID, paper.ExitTimeout, state.LatestTickerData.LastPrice, now)
+		emitSplashEvent(cfg, map[string]interface{}{
+			"symbol": t.symbol,
+			"status": models.StatusTimeout,
+		})
+		SaveReturnBackRecord(t.recordID, models.StatusTimeout, timeSinceTrigger, t.maxDeviation, dataGap)
+		resetTickerState(sh, t.symbol)
+		return true
+	}
+	// по устаревшей цене возврат не засчитываем — ждем свежих данных или таймаута
+	currentData := state.LatestTickerData
+	if stale || currentData.LastPrice <= 0 || currentData.FairPrice <= 0 || t.refLastPrice <= 0 || t.refFairPrice <= 0 {
+		return false
+	}
+	markPaperTrade(t.recordID, currentData.LastPrice, now)
+
+	lastPriceChangeToRef := math.Abs(currentData.LastPrice-t.refLastPrice) / t.refLastPrice
+	fairPriceChangeToRef := math.Abs(currentData.FairPrice-t.refFairPrice) / t.refFairPrice
+	currentDeviation := math.Max(lastPriceChangeToRef, fairPriceChangeToRef)
+
+	if currentDeviation > t.maxDeviation {
+		t.maxDeviation = currentDeviation
+	}
+
+	if currentDeviation <= tolerance {
+		timeToReturn := now.Sub(t.triggerTime)
+		log.Printf("PRICE RETURNED: %s | LEVEL: %.0f%%", t.symbol, currentLevel*100)
+		settlePaperTrade(t.recordID, paper.ExitReturned, currentData.LastPrice, now)
+
+		emitSplashEvent(cfg, map[string]interface{}{
+			"symbol":      t.symbol,
+			"status":      models.StatusReturned,
+			"returnTime":  fmt.Sprintf("%.2f", timeToReturn.Seconds()),
+			"lastPrice":   fmt.Sprintf("%.6f", currentData.LastPrice),
+			"fairPrice":   fmt.Sprintf("%.6f", currentData.FairPrice),
+			"isForcedPin": state.ForcedPin,
+		})
+		SaveReturnBackRecord(t.recordID, models.StatusReturned, timeToReturn, t.maxDeviation, dataGap)
+		resetTickerState(sh, t.symbol)
+		return true
+	}
+	return false
+}
 
-	state, ok := TockenState.TickerStates[symbol]
+func resetTickerState(sh *models.StateShard, symbol string) {
+	state, ok := sh.States[symbol]
 	if ok {
 		state.SplashTrigger = false
 		state.TriggerTime = time.Time{}
 		state.SplashDirection = ""
 		state.LastTriggeredLevel = 0.0
 		state.SplashRecordID = 0
+		state.LevelScale = 0
+		state.ForcedPin = false
+		state.Dismissed = false
+		// отработанное движение не должно сразу же дать новый сигнал
+		state.HistoryFrom = EngineClock.Now()
 
-		TockenState.TickerStates[symbol] = state
+		sh.States[symbol] = state
 	}
 }
 
-func SaveReturnBackRecord(recordID int64, returned bool, returnTime time.Duration, maxDeviation float64) {
-	record, err := database.GetSplashRecordByID(recordID)
-	if err != nil {
-		log.Printf("Error saving return back info for record ID %d: %v", recordID, err)
-		return
-	}
-	record.Returned = true
-	record.ReturnTime = returnTime
-	record.MaxDeviation = maxDeviation
-
-	err = database.UpdateSplashRecord(record)
-	if err != nil {
-		log.Printf("Error updating splash record ID %d: %v", recordID, err)
-		return
-	}
+// DismissSplash вручную снимает активный сплеш символа. Это единственный способ закрыть закрепленный
+// сплеш, который так и не вернулся. Запись закроется со статусом DISMISSED на следующем шаге слежения.
+func DismissSplash(symbol string) error {
+	var err error
+	TockenState.Do(symbol, func(sh *models.StateShard) {
+		state, ok := sh.States[symbol]
+		if !ok || !state.SplashTrigger {
+			err = fmt.Errorf("no active splash for %s", symbol)
+			return
+		}
+		state.Dismissed = true
+		sh.States[symbol] = state
+	})
+	return err
 }
 
 func dynamicTolerance(level float64) float64 {
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"splash-trading-bot/lib/models"
+)
+
+// TickFilter проверяет тикер символа до того, как он попадет в историю цен и детекцию.
+// state — состояние символа до тикера; фильтр может хранить в нем свои данные.
+// Возвращает причину отказа или пустую строку, если тикеру можно верить.
+type TickFilter interface {
+	Check(cfg *models.EngineConfig, state *models.TickerState, t models.SplashData) string
+}
+
+// TickFilters — цепочка проверок тикеров. Тикер отбрасывается первой отказавшей проверкой.
+// Подменяется только до запуска опроса: воркеры шардов читают ее без блокировок.
+var TickFilters = []TickFilter{PositivePrices{}, JumpFilter{}}
+
+// PositivePrices отбрасывает нулевые, отрицательные и нечисловые цены.
+type PositivePrices struct{}
+
+func (PositivePrices) Check(_ *models.EngineConfig, _ *models.TickerState, t models.SplashData) string {
+	if !validPrice(t.LastPrice) || !validPrice(t.FairPrice) {
+		return fmt.Sprintf("invalid price last=%v fair=%v", t.LastPrice, t.FairPrice)
+	}
+	return ""
+}
+
+func validPrice(p float64) bool {
+	return p > 0 && !math.IsInf(p, 0)
+}
+
+// JumpFilter отбрасывает одиночные выбросы: скачок больше cfg.Sanity.MaxJump от медианы последних
+// принтов принимается, только если большинство из последних MedianSize принтов уже на новом уровне.
+// Настоящее движение так подтверждается через пару снимков, а принт на один опрос не проходит.
+type JumpFilter struct{}
+
+func (JumpFilter) Check(cfg *models.EngineConfig, state *models.TickerState, t models.SplashData) string {
+	maxJump := cfg.Sanity.MaxJump / 100
+	if maxJump <= 0 {
+		state.RecentPrints = nil
+		return ""
+	}
+	// нулевые цены — забота PositivePrices, в медиану они не попадают
+	if !validPrice(t.LastPrice) || !validPrice(t.FairPrice) {
+		return ""
+	}
+	size := cfg.Sanity.MedianSize()
+
+	base, ok := medianPrint(state.RecentPrints)
+	prior := len(state.RecentPrints)
+	state.RecentPrints = appendPrint(state.RecentPrints, t, size)
+	if !ok {
+		return ""
+	}
+	jump := priceJump(t, base)
+	if jump <= maxJump {
+		return ""
+	}
+
+	near := 0
+	for _, p := range state.RecentPrints {
+		if priceJump(t, p) <= maxJump {
+			near++
+		}
+	}
+	if near > size/2 {
+		return ""
+	}
+	return fmt.Sprintf("jump %.1f%% from median of %d prints", jump*100, prior)
+}
+
+// appendPrint добавляет принт, оставляя не больше size последних.
+func appendPrint(prints []models.SplashData, t models.SplashData, size int) []models.SplashData {
+	if len(prints) >= size {
+		n := copy(prints, prints[len(prints)-size+1:])
+		prints = prints[:n]
+	}
+	return append(prints, t)
+}
+
+// medianPrint — медианы LastPrice и FairPrice по отдельности. При четном числе принтов берется нижняя.
+func medianPrint(prints []models.SplashData) (models.SplashData, bool) {
+	if len(prints) == 0 {
+		return models.SplashData{}, false
+	}
+	var buf [2][models.MaxMedianWindow]float64
+	last, fair := buf[0][:len(prints)], buf[1][:len(prints)]
+	for i, p := range prints {
+		last[i], fair[i] = p.LastPrice, p.FairPrice
+	}
+	slices.Sort(last)
+	slices.Sort(fair)
+	mid := (len(prints) - 1) / 2
+	return models.SplashData{LastPrice: last[mid], FairPrice: fair[mid]}, true
+}
+
+// priceJump — наибольшее относительное отклонение LastPrice и FairPrice тикера от ref.
+func priceJump(t, ref models.SplashData) float64 {
+	return math.Max(math.Abs(t.LastPrice-ref.LastPrice)/ref.LastPrice, math.Abs(t.FairPrice-ref.FairPrice)/ref.FairPrice)
+}
+
+// checkTick прогоняет тикер через TickFilters.
+func checkTick(cfg *models.EngineConfig, state *models.TickerState, t models.SplashData) string {
+	for _, f := range TickFilters {
+		if reason := f.Check(cfg, state, t); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+// confirmSplash считает снимки подряд, на которых держится сплеш направления direction, и разрешает
+// срабатывание, когда их наберется cfg.Sanity.ConfirmUpdates.
+func confirmSplash(cfg *models.EngineConfig, state *models.TickerState, direction string) bool {
+	if state.PendingDir != direction {
+		state.PendingDir, state.PendingHits = direction, 0
+	}
+	state.PendingHits++
+	if state.PendingHits < cfg.Sanity.ConfirmUpdates {
+		return false
+	}
+	state.PendingDir, state.PendingHits = "", 0
+	return true
+}
@@ -0,0 +1,189 @@
+package client
+
+import (
+	"math"
+	"splash-trading-bot/lib/models"
+	"testing"
+	"time"
+)
+
+func TestCheckTick(t *testing.T) {
+	inf, nan := math.Inf(1), math.NaN()
+	cases := []struct {
+		name    string
+		maxJump float64
+		prices  [][2]float64 // last, fair
+		reject  []bool
+	}{
+		{
+			name:    "single-print spike",
+			maxJump: 30,
+			prices:  [][2]float64{{100, 100}, {100, 100}, {100, 100}, {200, 200}, {100, 100}},
+			reject:  []bool{false, false, false, true, false},
+		},
+		{
+			name:    "move confirmed by two prints",
+			maxJump: 30,
+			prices:  [][2]float64{{100, 100}, {100, 100}, {100, 100}, {200, 200}, {200, 200}, {201, 201}},
+			reject:  []bool{false, false, false, true, false, false},
+		},
+		{
+			name:    "spike in fair price only",
+			maxJump: 30,
+			prices:  [][2]float64{{100, 100}, {100, 100}, {100, 100}, {100, 200}},
+			reject:  []bool{false, false, false, true},
+		},
+		{
+			name:    "move within max jump",
+			maxJump: 30,
+			prices:  [][2]float64{{100, 100}, {100, 100}, {129, 129}},
+			reject:  []bool{false, false, false},
+		},
+		{
+			name:    "filter disabled",
+			maxJump: 0,
+			prices:  [][2]float64{{100, 100}, {100, 100}, {1000, 1000}},
+			reject:  []bool{false, false, false},
+		},
+		{
+			name:    "non-positive prices",
+			maxJump: 0,
+			prices:  [][2]float64{{0, 100}, {100, 0}, {-1, -1}, {inf, 100}, {100, nan}, {100, 100}},
+			reject:  []bool{true, true, true, true, true, false},
+		},
+		{
+			name:    "bad prices stay out of the median",
+			maxJump: 30,
+			prices:  [][2]float64{{100, 100}, {0, 0}, {0, 0}, {0, 0}, {100, 100}},
+			reject:  []bool{false, true, true, true, false},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := models.DefaultConfig()
+			cfg.Sanity = models.SanityConfig{MaxJump: c.maxJump, MedianWindow: 3}
+			var state models.TickerState
+			for i, p := range c.prices {
+				reason := checkTick(&cfg, &state, models.SplashData{Symbol: "TEST_USDT", LastPrice: p[0], FairPrice: p[1]})
+				if rejected := reason != ""; rejected != c.reject[i] {
+					t.Fatalf("print %d (%v): rejected = %v (%q), want %v", i, p, rejected, reason, c.reject[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConfirmSplash(t *testing.T) {
+	cases := []struct {
+		confirm int
+		fireAt  int
+	}{
+		{0, 1},
+		{1, 1},
+		{3, 3},
+	}
+	for _, c := range cases {
+		cfg := models.DefaultConfig()
+		cfg.Sanity.ConfirmUpdates = c.confirm
+		var state models.TickerState
+		fired := 0
+		for i := 1; i <= 5 && fired == 0; i++ {
+			if confirmSplash(&cfg, &state, models.DirectionUp) {
+				fired = i
+			}
+		}
+		if fired != c.fireAt {
+			t.Errorf("ConfirmUpdates=%d: fired on snapshot %d, want %d", c.confirm, fired, c.fireAt)
+		}
+		if state.PendingDir != "" || state.PendingHits != 0 {
+			t.Errorf("ConfirmUpdates=%d: pending %s/%d left after firing", c.confirm, state.PendingDir, state.PendingHits)
+		}
+	}
+
+	// смена направления начинает счет заново
+	cfg := models.DefaultConfig()
+	cfg.Sanity.ConfirmUpdates = 2
+	var state models.TickerState
+	for i, dir := range []string{models.DirectionUp, models.DirectionDown, models.DirectionUp} {
+		if confirmSplash(&cfg, &state, dir) {
+			t.Fatalf("snapshot %d (%s) fired after a direction change", i, dir)
+		}
+	}
+	if !confirmSplash(&cfg, &state, models.DirectionUp) {
+		t.Fatal("second snapshot in the same direction did not fire")
+	}
+}
+
+// withSanity применяет к движку теста настройки фильтра плохих тиков.
+func withSanity(t *testing.T, sanity models.SanityConfig) {
+	t.Helper()
+	cfg := *models.Config()
+	cfg.Sanity = sanity
+	if errs := ApplyConfig(cfg); len(errs) > 0 {
+		t.Fatalf("invalid test config: %v", errs)
+	}
+}
+
+func TestPipelineConfirmUpdates(t *testing.T) {
+	cases := []struct {
+		name    string
+		confirm int
+		prices  []float64
+		fireAt  int // номер тикера после разгона, на котором появляется запись; 0 — не появляется
+	}{
+		{"disabled", 0, []float64{104}, 1},
+		{"one snapshot", 1, []float64{104}, 1},
+		{"three snapshots", 3, []float64{104, 104, 104}, 3},
+		{"reset when the splash disappears", 3, []float64{104, 104, 100, 104, 104, 104}, 6},
+		{"not held long enough", 3, []float64{104, 104, 100, 100}, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := newTestEngine(t, models.SplashTier{Level: 3, Window: 5})
+			withSanity(t, models.SanityConfig{MaxJump: 30, MedianWindow: 3, ConfirmUpdates: c.confirm})
+			for i := 0; i < 5; i++ {
+				e.tick(time.Second, 100)
+			}
+
+			fired := 0
+			for i, p := range c.prices {
+				e.tick(time.Second, p)
+				if fired == 0 && e.store.count() > 0 {
+					fired = i + 1
+				}
+			}
+			if fired != c.fireAt {
+				t.Fatalf("splash fired on tick %d, want %d", fired, c.fireAt)
+			}
+		})
+	}
+}
+
+func TestPipelineRejectsSpike(t *testing.T) {
+	cases := []struct {
+		name   string
+		prices []float64
+		fired  bool
+	}{
+		{"single-print spike", []float64{200, 100, 100}, false},
+		{"move confirmed by two prints", []float64{200, 200}, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := newTestEngine(t, models.SplashTier{Level: 3, Window: 5})
+			for i := 0; i < 5; i++ {
+				e.tick(time.Second, 100)
+			}
+			for _, p := range c.prices {
+				e.tick(time.Second, p)
+			}
+			if fired := e.store.count() > 0; fired != c.fired {
+				t.Fatalf("fired = %v, want %v", fired, c.fired)
+			}
+			if c.fired && e.store.record(t, 1).TriggerLastPrice != 200 {
+				t.Fatalf("trigger price = %v, want the confirmed 200", e.store.record(t, 1).TriggerLastPrice)
+			}
+		})
+	}
+}
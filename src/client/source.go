@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"log"
+	"splash-trading-bot/lib/clock"
+	"splash-trading-bot/lib/models"
+	"sync"
+	"time"
+)
+
+// EngineClock — часы движка. В live-режиме системные, в тестах и бэктесте подменяются на clock.Fake.
+var EngineClock clock.Clock = clock.Real
+
+// TickerSource отдает очередной снимок тикеров и момент его получения.
+// Когда данные закончились, возвращает io.EOF.
+type TickerSource interface {
+	Next(ctx context.Context) ([]models.SplashData, time.Time, error)
+}
+
+// SnapshotRecorder получает каждый снимок, прошедший через цикл опроса.
+type SnapshotRecorder interface {
+	Record(tickers []models.SplashData, at time.Time) error
+}
+
+var (
+	recorderMu sync.RWMutex
+	recorder   SnapshotRecorder
+)
+
+// SetRecorder включает запись сырых снимков; nil выключает ее.
+func SetRecorder(r SnapshotRecorder) {
+	recorderMu.Lock()
+	defer recorderMu.Unlock()
+	recorder = r
+}
+
+func recordSnapshot(tickers []models.SplashData, at time.Time) {
+	recorderMu.RLock()
+	r := recorder
+	recorderMu.RUnlock()
+
+	if r == nil {
+		return
+	}
+	if err := r.Record(tickers, at); err != nil {
+		log.Printf("Recorder error: %v", err)
+	}
+}
+
+// LiveSource опрашивает REST API MEXC с заданным интервалом. После ошибки следующий запрос
+// откладывается с backoff, а до этого Next сразу возвращает ErrFeedBackoff, чтобы цикл опроса
+// продолжал проверять таймауты сплешей.
+type LiveSource struct {
+	ticker  clock.Ticker
+	breaker feedBreaker
+}
+
+func NewLiveSource(interval time.Duration) *LiveSource {
+	return &LiveSource{ticker: EngineClock.NewTicker(interval)}
+}
+
+func (s *LiveSource) Next(ctx context.Context) ([]models.SplashData, time.Time, error) {
+	select {
+	case <-ctx.Done():
+		return nil, time.Time{}, ctx.Err()
+	case <-s.ticker.C():
+	}
+
+	now := EngineClock.Now()
+	if !s.breaker.allow(now) {
+		return nil, now, ErrFeedBackoff
+	}
+
+	tickers, err := FetchAllFuturesTickers(ctx)
+	if ctx.Err() != nil {
+		return nil, now, ctx.Err()
+	}
+	if err != nil {
+		s.breaker.failure(now, err)
+		return nil, now, err
+	}
+	s.breaker.success(now, EngineClock.Since(now))
+	return tickers, now, nil
+}
+
+func (s *LiveSource) Stop() {
+	s.ticker.Stop()
+}
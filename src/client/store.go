@@ -0,0 +1,51 @@
+package client
+
+import (
+	"splash-trading-bot/database"
+	"splash-trading-bot/lib/models"
+	"time"
+)
+
+// RecordStore — хранилище сплешей и бумажных сделок. В live-режиме это PostgreSQL,
+// бэктест подставляет собственную реализацию в памяти.
+type RecordStore interface {
+	GetContextStats(direction string, level int, volume float64, basisGap float64, window int, toleranceMode string, asOf time.Time) (int, int, error)
+	SaveSplashRecord(r models.SplashRecord, basisGap float64, speedSeconds float64) (int64, error)
+	UpdateSplashLevel(id int64, level int, lastPrice float64, fairPrice float64, volume24 float64, probWin float64, window int, toleranceMode string, tolerance float64, forcedPin bool) error
+	GetActiveSplashRecords() ([]models.SplashRecord, error)
+	CloseSplashRecord(id int64, status string, returnTime time.Duration, maxDeviation float64, dataGap bool) error
+	SavePaperTrade(t models.PaperTrade) (int64, error)
+	SaveConfigVersion(hash string, cfg models.EngineConfig) (int64, error)
+}
+
+var Store RecordStore = postgresStore{}
+
+type postgresStore struct{}
+
+func (postgresStore) GetContextStats(direction string, level int, volume float64, basisGap float64, window int, toleranceMode string, asOf time.Time) (int, int, error) {
+	return database.GetContextStats(direction, level, volume, basisGap, window, toleranceMode, asOf)
+}
+
+func (postgresStore) SaveSplashRecord(r models.SplashRecord, basisGap float64, speedSeconds float64) (int64, error) {
+	return database.SaveSplashRecord(r, basisGap, speedSeconds)
+}
+
+func (postgresStore) UpdateSplashLevel(id int64, level int, lastPrice float64, fairPrice float64, volume24 float64, probWin float64, window int, toleranceMode string, tolerance float64, forcedPin bool) error {
+	return database.UpdateSplashLevel(id, level, lastPrice, fairPrice, volume24, probWin, window, toleranceMode, tolerance, forcedPin)
+}
+
+func (postgresStore) GetActiveSplashRecords() ([]models.SplashRecord, error) {
+	return database.GetActiveSplashRecords()
+}
+
+func (postgresStore) CloseSplashRecord(id int64, status string, returnTime time.Duration, maxDeviation float64, dataGap bool) error {
+	return database.CloseSplashRecord(id, status, returnTime, maxDeviation, dataGap)
+}
+
+func (postgresStore) SavePaperTrade(t models.PaperTrade) (int64, error) {
+	return database.SavePaperTrade(t)
+}
+
+func (postgresStore) SaveConfigVersion(hash string, cfg models.EngineConfig) (int64, error) {
+	return database.SaveConfigVersion(hash, cfg)
+}
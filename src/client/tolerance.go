@@ -0,0 +1,70 @@
+package client
+
+import (
+	"math"
+	"splash-trading-bot/lib/models"
+	"time"
+)
+
+// returnTolerance определяет, насколько близко цена должна вернуться к референсу, чтобы сплеш
+// считался отработанным. splashSize — фактическое отклонение в момент срабатывания (доля).
+// Если режим не задан или для него не хватает данных, используется прежняя формула dynamicTolerance.
+func returnTolerance(tier models.SplashTier, splashSize float64, state models.TickerState) (string, float64) {
+	level := math.Round(tier.Level) / 100
+	if tier.Tolerance <= 0 {
+		return models.ToleranceAuto, dynamicTolerance(level)
+	}
+
+	switch tier.ToleranceMode {
+	case models.TolerancePercent:
+		return tier.ToleranceMode, tier.Tolerance / 100
+	case models.ToleranceFraction:
+		if splashSize > 0 {
+			return tier.ToleranceMode, tier.Tolerance * splashSize
+		}
+	case models.ToleranceATR:
+		if state.AtrBars >= models.AtrMinBars && state.Atr > 0 {
+			return tier.ToleranceMode, tier.Tolerance * state.Atr
+		}
+	}
+	return models.ToleranceAuto, dynamicTolerance(level)
+}
+
+// updateAtr ведет минутные бары по LastPrice и сглаженный по Уайлдеру ATR в долях от цены.
+func updateAtr(state *models.TickerState, t models.SplashData, now time.Time) {
+	price := t.LastPrice
+	if price <= 0 {
+		return
+	}
+
+	if state.BarStart.IsZero() {
+		state.BarStart = now
+		state.BarHigh, state.BarLow, state.BarClose = price, price, price
+		return
+	}
+
+	if now.Sub(state.BarStart) >= models.AtrBarPeriod {
+		high, low := state.BarHigh, state.BarLow
+		if state.PrevClose > 0 {
+			high = math.Max(high, state.PrevClose)
+			low = math.Min(low, state.PrevClose)
+		}
+		trueRange := (high - low) / state.BarClose
+
+		if state.AtrBars == 0 {
+			state.Atr = trueRange
+		} else {
+			state.Atr += (trueRange - state.Atr) / models.AtrPeriod
+		}
+		state.AtrBars++
+		updateVolatility(state)
+
+		state.PrevClose = state.BarClose
+		state.BarStart = now
+		state.BarHigh, state.BarLow = price, price
+	}
+
+	state.BarHigh = math.Max(state.BarHigh, price)
+	state.BarLow = math.Min(state.BarLow, price)
+	state.BarClose = price
+}
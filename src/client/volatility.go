@@ -0,0 +1,38 @@
+package client
+
+import (
+	"math"
+	"splash-trading-bot/lib/models"
+)
+
+// updateVolatility обновляет экспоненциально сглаженную дисперсию минутных лог-доходностей.
+// Вызывается при закрытии бара, пока PrevClose еще указывает на закрытие предыдущего.
+func updateVolatility(state *models.TickerState) {
+	if state.PrevClose <= 0 || state.BarClose <= 0 {
+		return
+	}
+
+	r := math.Log(state.BarClose / state.PrevClose)
+	if state.VolBars == 0 {
+		state.RealizedVar = r * r
+	} else {
+		state.RealizedVar += (r*r - state.RealizedVar) * 2 / (models.VolPeriod + 1)
+	}
+	state.VolBars++
+}
+
+// levelScale — множитель уровней тиров для символа. Пока баров мало или подстройка выключена, равен 1.
+func levelScale(state models.TickerState, cfg models.AdaptiveConfig) float64 {
+	if !cfg.Enabled || cfg.Baseline <= 0 || state.VolBars < models.VolMinBars {
+		return 1
+	}
+
+	scale := math.Sqrt(state.RealizedVar) * 100 / cfg.Baseline
+	if cfg.MinScale > 0 {
+		scale = math.Max(scale, cfg.MinScale)
+	}
+	if cfg.MaxScale > 0 {
+		scale = math.Min(scale, cfg.MaxScale)
+	}
+	return math.Round(scale*100) / 100
+}
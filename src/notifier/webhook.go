@@ -0,0 +1,346 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"splash-trading-bot/lib/models"
+	"strconv"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const (
+	maxAttempts  = 5
+	baseBackoff  = 500 * time.Millisecond
+	maxBackoff   = 30 * time.Second
+	queueSize    = 256
+	workersCount = 4
+)
+
+// DeadLetterPath — файл, куда пишутся вебхуки, которые так и не удалось доставить.
+var DeadLetterPath = "webhook_deadletter.log"
+
+var httpClient = &http.Client{
+	Timeout: 5 * time.Second,
+}
+
+var (
+	poolMu    sync.RWMutex
+	pool      *workerPool
+	templates sync.Map
+	deadMu    sync.Mutex
+)
+
+// workerPool — очередь доставки и ее воркеры. Запускается первым Notify, останавливается Shutdown;
+// следующий Notify запускает новый пул.
+type workerPool struct {
+	queue  chan delivery
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type delivery struct {
+	URL    string
+	Secret string
+	Body   []byte
+}
+
+type deadLetter struct {
+	Time     time.Time       `json:"time"`
+	URL      string          `json:"url"`
+	Attempts int             `json:"attempts"`
+	Error    string          `json:"error"`
+	Body     json.RawMessage `json:"body,omitempty"`
+	RawBody  string          `json:"rawBody,omitempty"`
+}
+
+// Notify рендерит событие для каждого подходящего вебхука и ставит доставку в очередь.
+// Вызов не блокирует движок: при переполненной очереди событие сразу уходит в dead-letter лог.
+func Notify(hooks []models.WebhookConfig, event map[string]interface{}) {
+	if len(hooks) == 0 {
+		return
+	}
+	// под RLock Shutdown не закроет очередь посреди отправки
+	poolMu.RLock()
+	for pool == nil {
+		poolMu.RUnlock()
+		poolMu.Lock()
+		if pool == nil {
+			pool = startWorkers()
+		}
+		poolMu.Unlock()
+		poolMu.RLock()
+	}
+	p := pool
+	defer poolMu.RUnlock()
+
+	status, _ := event["status"].(string)
+	for _, hook := range hooks {
+		if hook.URL == "" || !matchStatus(hook.Statuses, status) {
+			continue
+		}
+
+		body, err := render(hook, event)
+		if err != nil {
+			log.Printf("Webhook render error for %s: %v", hook.URL, err)
+			writeDeadLetter(delivery{URL: hook.URL}, 0, err)
+			continue
+		}
+
+		d := delivery{URL: hook.URL, Secret: hook.Secret, Body: body}
+		select {
+		case p.queue <- d:
+		default:
+			writeDeadLetter(d, 0, fmt.Errorf("delivery queue is full"))
+		}
+	}
+}
+
+func startWorkers() *workerPool {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &workerPool{queue: make(chan delivery, queueSize), ctx: ctx, cancel: cancel}
+	for i := 0; i < workersCount; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for d := range p.queue {
+				deliver(p.ctx, d)
+			}
+		}()
+	}
+	return p
+}
+
+// Shutdown перестает принимать события и дает воркерам до timeout, чтобы доставить очередь.
+// По истечении времени запросы и паузы между попытками прерываются, недоставленное уходит в dead-letter лог.
+func Shutdown(timeout time.Duration) {
+	poolMu.Lock()
+	p := pool
+	pool = nil
+	poolMu.Unlock()
+	if p == nil {
+		return
+	}
+
+	close(p.queue)
+	timer := time.AfterFunc(timeout, p.cancel)
+	p.wg.Wait()
+	timer.Stop()
+	p.cancel()
+}
+
+func matchStatus(statuses []string, status string) bool {
+	if len(statuses) == 0 {
+		return true
+	}
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func render(hook models.WebhookConfig, event map[string]interface{}) ([]byte, error) {
+	if hook.Template == "" {
+		return json.Marshal(event)
+	}
+
+	tmpl, err := parseTemplate(hook.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return nil, fmt.Errorf("template execute: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ValidateTemplate проверяет, что шаблон вебхука разбирается. Пустой шаблон означает JSON события.
+func ValidateTemplate(text string) error {
+	if text == "" {
+		return nil
+	}
+	_, err := parseTemplate(text)
+	return err
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	if cached, ok := templates.Load(text); ok {
+		return cached.(*template.Template), nil
+	}
+
+	tmpl, err := template.New("webhook").Option("missingkey=zero").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("template parse: %w", err)
+	}
+
+	templates.Store(text, tmpl)
+	return tmpl, nil
+}
+
+// StatusError — получатель ответил не 2xx. Повторять имеет смысл только 429 и 5xx,
+// остальные коды означают, что запрос не примут и со второй попытки.
+type StatusError struct {
+	Status     int
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook error: status %d", e.Status)
+}
+
+func (e *StatusError) Temporary() bool {
+	return e.Status == http.StatusTooManyRequests || e.Status >= 500
+}
+
+// deliver отправляет вебхук, повторяя сетевые ошибки, 429 и 5xx с backoff. Постоянные ошибки,
+// исчерпанные попытки и доставки, прерванные отменой ctx, уходят в dead-letter лог.
+func deliver(ctx context.Context, d delivery) {
+	attempt := 1
+	for ; ; attempt++ {
+		if ctx.Err() != nil {
+			writeDeadLetter(d, attempt-1, fmt.Errorf("notifier stopped"))
+			return
+		}
+		err := post(ctx, d)
+		if err == nil {
+			return
+		}
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && !statusErr.Temporary() {
+			log.Printf("Webhook delivery to %s rejected: %v", d.URL, err)
+			writeDeadLetter(d, attempt, err)
+			return
+		}
+		if attempt == maxAttempts {
+			log.Printf("Webhook delivery to %s failed after %d attempts: %v", d.URL, attempt, err)
+			writeDeadLetter(d, attempt, err)
+			return
+		}
+
+		wait := backoff(attempt)
+		if statusErr != nil && statusErr.RetryAfter > wait {
+			wait = statusErr.RetryAfter
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			log.Printf("Webhook delivery to %s abandoned on shutdown: %v", d.URL, err)
+			writeDeadLetter(d, attempt, fmt.Errorf("notifier stopped: %w", err))
+			return
+		}
+	}
+}
+
+func post(ctx context.Context, d delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Terminus-Splash-Notifier")
+
+	if d.Secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Splash-Timestamp", ts)
+		req.Header.Set("X-Splash-Signature", "sha256="+Sign(d.Secret, ts, d.Body))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return &StatusError{Status: resp.StatusCode, RetryAfter: retryAfter(resp.Header, time.Now())}
+}
+
+// retryAfter разбирает Retry-After в секундах или в виде HTTP-даты. Пауза не больше maxBackoff,
+// чтобы один получатель не занимал воркер надолго.
+func retryAfter(h http.Header, now time.Time) time.Duration {
+	var d time.Duration
+	value := h.Get("Retry-After")
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		d = time.Duration(secs) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		d = at.Sub(now)
+	}
+	return min(d, maxBackoff)
+}
+
+// Sign считает HMAC-SHA256 от "timestamp.body", чтобы получатель мог проверить подпись и свежесть запроса.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func backoff(attempt int) time.Duration {
+	d := baseBackoff << (attempt - 1)
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func writeDeadLetter(d delivery, attempts int, cause error) {
+	entry := deadLetter{
+		Time:     time.Now(),
+		URL:      d.URL,
+		Attempts: attempts,
+		Error:    cause.Error(),
+	}
+	if json.Valid(d.Body) {
+		entry.Body = d.Body
+	} else {
+		entry.RawBody = string(d.Body)
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("Webhook dead-letter encode error: %v", err)
+		return
+	}
+
+	deadMu.Lock()
+	defer deadMu.Unlock()
+
+	f, err := os.OpenFile(DeadLetterPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		log.Printf("Webhook dead-letter open error: %v", err)
+		return
+	}
+	defer f.Close()
+
+	f.Write(append(line, '\n'))
+}
@@ -0,0 +1,137 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"splash-trading-bot/lib/models"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// server отвечает по очереди кодами из codes, последний код повторяется.
+func server(t *testing.T, codes ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(hits.Add(1))
+		w.WriteHeader(codes[min(n, len(codes))-1])
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func deadLetters(t *testing.T) []deadLetter {
+	t.Helper()
+	data, err := os.ReadFile(DeadLetterPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []deadLetter
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry deadLetter
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func withDeadLetterFile(t *testing.T) {
+	t.Helper()
+	prev := DeadLetterPath
+	DeadLetterPath = filepath.Join(t.TempDir(), "deadletter.log")
+	t.Cleanup(func() { DeadLetterPath = prev })
+}
+
+func TestDeliverDoesNotRetryPermanentErrors(t *testing.T) {
+	for _, code := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound} {
+		withDeadLetterFile(t)
+		srv, hits := server(t, code)
+
+		deliver(context.Background(), delivery{URL: srv.URL, Body: []byte(`{"status":"ACTIVE"}`)})
+
+		if got := hits.Load(); got != 1 {
+			t.Fatalf("status %d: %d attempts, want 1", code, got)
+		}
+		dead := deadLetters(t)
+		if len(dead) != 1 || dead[0].Attempts != 1 {
+			t.Fatalf("status %d: dead letters = %+v, want one entry after 1 attempt", code, dead)
+		}
+	}
+}
+
+func TestDeliverRetriesServerErrors(t *testing.T) {
+	withDeadLetterFile(t)
+	srv, hits := server(t, http.StatusServiceUnavailable, http.StatusOK)
+
+	deliver(context.Background(), delivery{URL: srv.URL, Body: []byte(`{}`)})
+
+	if got := hits.Load(); got != 2 {
+		t.Fatalf("%d attempts, want 2", got)
+	}
+	if dead := deadLetters(t); len(dead) != 0 {
+		t.Fatalf("dead letters = %+v, want none", dead)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"3600", maxBackoff},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second},
+		{"soon", 0},
+	}
+	for _, c := range cases {
+		h := http.Header{}
+		if c.value != "" {
+			h.Set("Retry-After", c.value)
+		}
+		if got := retryAfter(h, now); got != c.want {
+			t.Errorf("retryAfter(%q) = %s, want %s", c.value, got, c.want)
+		}
+	}
+}
+
+func TestShutdownAbandonsRetries(t *testing.T) {
+	withDeadLetterFile(t)
+	srv, hits := server(t, http.StatusServiceUnavailable)
+	hooks := []models.WebhookConfig{{URL: srv.URL}}
+
+	Notify(hooks, map[string]interface{}{"status": models.StatusCancelled})
+	for hits.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	started := time.Now()
+	Shutdown(50 * time.Millisecond)
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Fatalf("Shutdown took %s, want it to stop waiting after the timeout", elapsed)
+	}
+	dead := deadLetters(t)
+	if len(dead) != 1 || !strings.Contains(dead[0].Error, "notifier stopped") {
+		t.Fatalf("dead letters = %+v, want the abandoned delivery", dead)
+	}
+
+	// следующий сигнал снова запускает доставку
+	ok, okHits := server(t, http.StatusOK)
+	Notify([]models.WebhookConfig{{URL: ok.URL}}, map[string]interface{}{"status": models.StatusActive})
+	Shutdown(time.Second)
+	if okHits.Load() != 1 {
+		t.Fatalf("delivery after restart: %d hits, want 1", okHits.Load())
+	}
+}
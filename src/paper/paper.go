@@ -0,0 +1,153 @@
+package paper
+
+import (
+	"math"
+	"splash-trading-bot/lib/models"
+	"sync"
+	"time"
+)
+
+const (
+	ExitReturned  = "RETURNED"
+	ExitStopLoss  = "STOP_LOSS"
+	ExitTimeout   = "TIMEOUT"
+	ExitCancelled = "CANCELLED"
+)
+
+type position struct {
+	trade    models.PaperTrade
+	qty      float64
+	feeRate  float64
+	slippage float64
+}
+
+var book = struct {
+	sync.Mutex
+	open map[int64]*position
+}{open: make(map[int64]*position)}
+
+// Open открывает контртрендовую позицию на сплеш: UP -> SHORT, DOWN -> LONG.
+// Вход исполняется по цене триггера с учетом проскальзывания и комиссии.
+func Open(recordID int64, symbol, direction string, level int, price float64, now time.Time, cfg models.PaperConfig) (models.PaperTrade, bool) {
+	if price <= 0 || cfg.PositionSize <= 0 {
+		return models.PaperTrade{}, false
+	}
+
+	book.Lock()
+	defer book.Unlock()
+
+	if _, exists := book.open[recordID]; exists {
+		return models.PaperTrade{}, false
+	}
+
+	side := "LONG"
+	if direction == "UP" {
+		side = "SHORT"
+	}
+
+	slippage := cfg.Slippage / 100
+	feeRate := cfg.FeeRate / 100
+	entry := fill(side, price, slippage, true)
+	qty := cfg.PositionSize / entry
+
+	stop := 0.0
+	if cfg.StopLoss > 0 {
+		if side == "SHORT" {
+			stop = entry * (1 + cfg.StopLoss/100)
+		} else {
+			stop = entry * (1 - cfg.StopLoss/100)
+		}
+	}
+
+	p := &position{
+		trade: models.PaperTrade{
+			RecordID:   recordID,
+			Symbol:     symbol,
+			Side:       side,
+			Level:      level,
+			Size:       cfg.PositionSize,
+			EntryPrice: entry,
+			StopPrice:  stop,
+			EntryTime:  now,
+			Fees:       cfg.PositionSize * feeRate,
+		},
+		qty:      qty,
+		feeRate:  feeRate,
+		slippage: slippage,
+	}
+	book.open[recordID] = p
+
+	return p.trade, true
+}
+
+// Mark проверяет стоп-лосс по текущей цене. Возвращает закрытую сделку, если стоп сработал.
+func Mark(recordID int64, price float64, now time.Time) (models.PaperTrade, bool) {
+	if price <= 0 {
+		return models.PaperTrade{}, false
+	}
+
+	book.Lock()
+	defer book.Unlock()
+
+	p, ok := book.open[recordID]
+	if !ok || p.trade.StopPrice == 0 {
+		return models.PaperTrade{}, false
+	}
+
+	hit := price >= p.trade.StopPrice
+	if p.trade.Side == "LONG" {
+		hit = price <= p.trade.StopPrice
+	}
+	if !hit {
+		return models.PaperTrade{}, false
+	}
+
+	return closeLocked(recordID, p, ExitStopLoss, price, now), true
+}
+
+// Close закрывает позицию по рыночной цене (возврат к референсу, таймаут окна или отмена).
+func Close(recordID int64, reason string, price float64, now time.Time) (models.PaperTrade, bool) {
+	book.Lock()
+	defer book.Unlock()
+
+	p, ok := book.open[recordID]
+	if !ok {
+		return models.PaperTrade{}, false
+	}
+	if price <= 0 {
+		price = p.trade.EntryPrice
+	}
+
+	return closeLocked(recordID, p, reason, price, now), true
+}
+
+func closeLocked(recordID int64, p *position, reason string, price float64, now time.Time) models.PaperTrade {
+	delete(book.open, recordID)
+
+	exit := fill(p.trade.Side, price, p.slippage, false)
+	gross := p.qty * (exit - p.trade.EntryPrice)
+	if p.trade.Side == "SHORT" {
+		gross = -gross
+	}
+
+	t := p.trade
+	t.ExitPrice = exit
+	t.ExitTime = now
+	t.ExitReason = reason
+	t.Fees += p.qty * exit * p.feeRate
+	t.PnL = gross - t.Fees
+	t.PnLPercent = t.PnL / t.Size * 100
+	if math.IsNaN(t.PnLPercent) {
+		t.PnLPercent = 0
+	}
+	return t
+}
+
+// fill сдвигает цену против нас на величину проскальзывания.
+func fill(side string, price, slippage float64, entry bool) float64 {
+	buying := (side == "LONG") == entry
+	if buying {
+		return price * (1 + slippage)
+	}
+	return price * (1 - slippage)
+}
@@ -0,0 +1,225 @@
+package recorder
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"splash-trading-bot/lib/models"
+	"strings"
+	"time"
+)
+
+// FileReader читает снимки из одного файла записи.
+type FileReader struct {
+	path    string
+	file    *os.File
+	gz      *gzip.Reader
+	r       *bufio.Reader
+	symbols []string
+	body    []byte
+}
+
+func OpenFile(path string) (*FileReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("recorder: failed to open %s: %w", path, err)
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("recorder: %s is not a tick file: %w", path, err)
+	}
+
+	r := bufio.NewReaderSize(gz, 64<<10)
+	head := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(r, head); err != nil || string(head[:len(magic)]) != magic {
+		gz.Close()
+		f.Close()
+		return nil, fmt.Errorf("recorder: %s has invalid header", path)
+	}
+	if head[len(magic)] != version {
+		gz.Close()
+		f.Close()
+		return nil, fmt.Errorf("recorder: %s has unsupported version %d", path, head[len(magic)])
+	}
+
+	return &FileReader{path: path, file: f, gz: gz, r: r}, nil
+}
+
+// Next возвращает следующий снимок или io.EOF в конце файла.
+// Оборванная последняя запись (например, после падения процесса) тоже считается концом файла.
+func (fr *FileReader) Next() (Frame, error) {
+	for {
+		size, err := binary.ReadUvarint(fr.r)
+		if err != nil {
+			if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+				return Frame{}, io.EOF
+			}
+			return Frame{}, fmt.Errorf("recorder: %s: %w", fr.path, err)
+		}
+		if size == 0 {
+			return Frame{}, fmt.Errorf("recorder: %s: empty record", fr.path)
+		}
+		if size > MaxRecordSize {
+			return Frame{}, fmt.Errorf("recorder: %s: record of %d bytes exceeds the %d byte limit", fr.path, size, MaxRecordSize)
+		}
+
+		if cap(fr.body) < int(size) {
+			fr.body = make([]byte, size)
+		}
+		rec := fr.body[:size]
+		if _, err := io.ReadFull(fr.r, rec); err != nil {
+			return Frame{}, io.EOF
+		}
+
+		switch rec[0] {
+		case recSymbol:
+			if err := fr.readSymbol(rec[1:]); err != nil {
+				return Frame{}, err
+			}
+		case recFrame:
+			return fr.readFrame(rec[1:])
+		default:
+			// неизвестные записи пропускаем для совместимости с будущими версиями
+		}
+	}
+}
+
+func (fr *FileReader) Close() error {
+	fr.gz.Close()
+	return fr.file.Close()
+}
+
+func (fr *FileReader) readSymbol(body []byte) error {
+	id, n := binary.Uvarint(body)
+	if n <= 0 || id != uint64(len(fr.symbols)) {
+		return fmt.Errorf("recorder: %s: corrupted symbol dictionary", fr.path)
+	}
+	fr.symbols = append(fr.symbols, string(body[n:]))
+	return nil
+}
+
+// minTickerSize — наименьший размер тикера в кадре: id символа (varint) и три float64.
+const minTickerSize = 1 + 24
+
+func (fr *FileReader) readFrame(body []byte) (Frame, error) {
+	corrupted := fmt.Errorf("recorder: %s: corrupted frame", fr.path)
+
+	ms, n := binary.Uvarint(body)
+	if n <= 0 {
+		return Frame{}, corrupted
+	}
+	body = body[n:]
+	count, n := binary.Uvarint(body)
+	if n <= 0 {
+		return Frame{}, corrupted
+	}
+	body = body[n:]
+	// каждый тикер занимает хотя бы minTickerSize байт, иначе счетчик испорчен
+	if count > uint64(len(body))/minTickerSize {
+		return Frame{}, corrupted
+	}
+
+	frame := Frame{
+		Time:    time.UnixMilli(int64(ms)),
+		Tickers: make([]models.SplashData, 0, count),
+	}
+	for i := uint64(0); i < count; i++ {
+		id, n := binary.Uvarint(body)
+		if n <= 0 || id >= uint64(len(fr.symbols)) || len(body[n:]) < 24 {
+			return Frame{}, corrupted
+		}
+		body = body[n:]
+		frame.Tickers = append(frame.Tickers, models.SplashData{
+			Symbol:    fr.symbols[id],
+			LastPrice: math.Float64frombits(binary.LittleEndian.Uint64(body[0:8])),
+			FairPrice: math.Float64frombits(binary.LittleEndian.Uint64(body[8:16])),
+			Volume24:  math.Float64frombits(binary.LittleEndian.Uint64(body[16:24])),
+		})
+		body = body[24:]
+	}
+	return frame, nil
+}
+
+// Reader последовательно читает набор файлов записи в хронологическом порядке.
+type Reader struct {
+	paths   []string
+	current *FileReader
+}
+
+// Open принимает путь к одному файлу или к каталогу с записью.
+func Open(path string) (*Reader, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("recorder: %w", err)
+	}
+	if !info.IsDir() {
+		return &Reader{paths: []string{path}}, nil
+	}
+
+	paths, err := ListFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("recorder: no tick files in %s", path)
+	}
+	return &Reader{paths: paths}, nil
+}
+
+// ListFiles возвращает файлы записи в каталоге, отсортированные по времени начала.
+func ListFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("recorder: %w", err)
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), fileExt) {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func (r *Reader) Next() (Frame, error) {
+	for {
+		if r.current == nil {
+			if len(r.paths) == 0 {
+				return Frame{}, io.EOF
+			}
+			fr, err := OpenFile(r.paths[0])
+			if err != nil {
+				return Frame{}, err
+			}
+			r.paths = r.paths[1:]
+			r.current = fr
+		}
+
+		frame, err := r.current.Next()
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			continue
+		}
+		return frame, err
+	}
+}
+
+func (r *Reader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
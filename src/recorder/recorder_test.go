@@ -0,0 +1,258 @@
+package recorder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"splash-trading-bot/lib/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func testFrames(n int) []Frame {
+	frames := make([]Frame, n)
+	for i := range frames {
+		frames[i] = Frame{
+			Time: start.Add(time.Duration(i) * 100 * time.Millisecond),
+			Tickers: []models.SplashData{
+				{Symbol: "BTC_USDT", LastPrice: 60000 + float64(i), FairPrice: 60001 + float64(i), Volume24: 1e9},
+				{Symbol: "ETH_USDT", LastPrice: 3000.5, FairPrice: 3000.25, Volume24: 5e8},
+			},
+		}
+		// новый символ посреди файла должен попасть в словарь
+		if i == n/2 {
+			frames[i].Tickers = append(frames[i].Tickers, models.SplashData{Symbol: "NEW_USDT", LastPrice: 0.001, FairPrice: 0.0011, Volume24: 1e5})
+		}
+	}
+	return frames
+}
+
+func writeFrames(t *testing.T, opts Options, frames []Frame) {
+	t.Helper()
+	w, err := NewWriter(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range frames {
+		if err := w.Record(f.Tickers, f.Time); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readAll(t *testing.T, path string) []Frame {
+	t.Helper()
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var frames []Frame
+	for {
+		f, err := r.Next()
+		if err == io.EOF {
+			return frames
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames = append(frames, f)
+	}
+}
+
+func equalFrames(t *testing.T, got, want []Frame) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("read %d frames, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Time.Equal(want[i].Time) || !reflect.DeepEqual(got[i].Tickers, want[i].Tickers) {
+			t.Fatalf("frame %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	frames := testFrames(50)
+	writeFrames(t, Options{Dir: dir}, frames)
+
+	equalFrames(t, readAll(t, dir), frames)
+}
+
+func TestRoundTripAcrossRotation(t *testing.T) {
+	dir := t.TempDir()
+	frames := testFrames(50)
+	writeFrames(t, Options{Dir: dir, RotateEvery: time.Second}, frames)
+
+	files, err := ListFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 5 {
+		t.Fatalf("files = %d, want 5 one-second files", len(files))
+	}
+	equalFrames(t, readAll(t, dir), frames)
+}
+
+// gunzip и gzipBytes нужны, чтобы испортить поток записей внутри корректного gzip.
+func gunzip(t *testing.T, path string) []byte {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func gzipBytes(t *testing.T, path string, data []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(data)
+	gz.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func singleFile(t *testing.T, dir string) string {
+	t.Helper()
+	files, err := ListFiles(dir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("files = %v (%v), want exactly one", files, err)
+	}
+	return files[0]
+}
+
+func TestTruncatedTrailingRecord(t *testing.T) {
+	dir := t.TempDir()
+	frames := testFrames(20)
+	writeFrames(t, Options{Dir: dir}, frames)
+	path := singleFile(t, dir)
+
+	// процесс упал посреди последней записи: в файле остались ее длина и часть тела
+	data := gunzip(t, path)
+	gzipBytes(t, path, data[:len(data)-10])
+
+	equalFrames(t, readAll(t, path), frames[:len(frames)-1])
+}
+
+func TestTruncatedGzipStream(t *testing.T) {
+	dir := t.TempDir()
+	frames := testFrames(20)
+	writeFrames(t, Options{Dir: dir}, frames)
+	path := singleFile(t, dir)
+
+	// оборван сам gzip: нет хвоста с контрольной суммой
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, raw[:len(raw)-8], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readAll(t, path)
+	if len(got) > len(frames) {
+		t.Fatalf("read %d frames from %d written", len(got), len(frames))
+	}
+	equalFrames(t, got, frames[:len(got)])
+}
+
+func TestOversizedRecordRejected(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ticks-bad"+fileExt)
+	data := append([]byte(magic), version)
+	data = binary.AppendUvarint(data, 1<<40)
+	data = append(data, recFrame)
+	gzipBytes(t, path, data)
+
+	r, err := OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	_, err = r.Next()
+	if err == nil || errors.Is(err, io.EOF) || !strings.Contains(err.Error(), "exceeds") {
+		t.Fatalf("Next() error = %v, want a record size error", err)
+	}
+}
+
+func TestMaxFileSizeCountsCompressedBytes(t *testing.T) {
+	dir := t.TempDir()
+	// случайные цены почти не сжимаются, иначе весь прогон уместится в один файл
+	rng := rand.New(rand.NewSource(1))
+	frames := testFrames(4000)
+	for _, f := range frames {
+		for i := range f.Tickers {
+			f.Tickers[i].LastPrice = rng.Float64()
+			f.Tickers[i].FairPrice = rng.Float64()
+		}
+	}
+	writeFrames(t, Options{Dir: dir, MaxFileSize: 32 << 10}, frames)
+
+	files, err := ListFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) < 2 {
+		t.Fatalf("files = %d, want the size limit to rotate", len(files))
+	}
+	for _, path := range files[:len(files)-1] {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// файл ротируется, только когда на диске набралось 32 КиБ, и превышает предел не больше чем
+		// на одну порцию сжатых данных
+		if info.Size() < 32<<10 || info.Size() > 32<<10+64<<10 {
+			t.Fatalf("%s is %d bytes, limit was 32KiB", filepath.Base(path), info.Size())
+		}
+	}
+	equalFrames(t, readAll(t, dir), frames)
+}
+
+func TestHugeTickerCountRejected(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ticks-bad"+fileExt)
+	body := []byte{recFrame}
+	body = binary.AppendUvarint(body, uint64(start.UnixMilli()))
+	body = binary.AppendUvarint(body, 1<<60)
+	data := append([]byte(magic), version)
+	data = binary.AppendUvarint(data, uint64(len(body)))
+	data = append(data, body...)
+	gzipBytes(t, path, data)
+
+	r, err := OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	_, err = r.Next()
+	if err == nil || errors.Is(err, io.EOF) || !strings.Contains(err.Error(), "corrupted frame") {
+		t.Fatalf("Next() error = %v, want a corrupted frame error", err)
+	}
+}
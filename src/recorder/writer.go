@@ -0,0 +1,220 @@
+package recorder
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"splash-trading-bot/lib/models"
+	"sync"
+	"time"
+)
+
+// Формат файла (внутри gzip):
+//
+//	magic "SPLT" + версия (1 байт)
+//	далее записи: uvarint длина | тип (1 байт) | тело
+//	  recSymbol: uvarint id | строка символа
+//	  recFrame:  uvarint unix ms | uvarint кол-во | (uvarint id, float64 last, float64 fair, float64 vol24)*
+//
+// Словарь символов свой у каждого файла, поэтому любой файл читается независимо.
+const (
+	magic   = "SPLT"
+	version = 1
+
+	recSymbol byte = 'S'
+	recFrame  byte = 'F'
+
+	fileExt = ".splt.gz"
+)
+
+// MaxRecordSize — предел длины одной записи. Читатель не доверяет длине с диска больше этого,
+// чтобы испорченный файл не заставил выделить гигабайты; писатель такие записи не пишет.
+const MaxRecordSize = 16 << 20
+
+const (
+	DefaultRotateEvery = time.Hour
+	DefaultMaxFileSize = 256 << 20
+	flushEvery         = 10 * time.Second
+)
+
+// Frame — один снимок всех тикеров в момент опроса.
+type Frame struct {
+	Time    time.Time
+	Tickers []models.SplashData
+}
+
+// MaxFileSize — предел размера файла на диске, то есть сжатых байт. Сжатые данные доходят до файла
+// порциями, поэтому файл может превысить предел на размер буфера gzip.
+type Options struct {
+	Dir         string
+	RotateEvery time.Duration
+	MaxFileSize int64
+}
+
+// Writer пишет снимки в ротируемые сжатые файлы. Безопасен для конкурентного использования.
+type Writer struct {
+	mu   sync.Mutex
+	opts Options
+
+	file      *os.File
+	counter   *countingWriter
+	gz        *gzip.Writer
+	buf       *bufio.Writer
+	symbols   map[string]uint64
+	opened    time.Time
+	lastFlush time.Time
+	scratch   []byte
+}
+
+func NewWriter(opts Options) (*Writer, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("recorder: output directory is required")
+	}
+	if opts.RotateEvery <= 0 {
+		opts.RotateEvery = DefaultRotateEvery
+	}
+	if opts.MaxFileSize <= 0 {
+		opts.MaxFileSize = DefaultMaxFileSize
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("recorder: failed to create %s: %w", opts.Dir, err)
+	}
+	return &Writer{opts: opts}, nil
+}
+
+// Record дописывает снимок, при необходимости открывая новый файл.
+func (w *Writer) Record(tickers []models.SplashData, at time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil || at.Sub(w.opened) >= w.opts.RotateEvery || w.counter.n >= w.opts.MaxFileSize {
+		if err := w.rotate(at); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range tickers {
+		if _, ok := w.symbols[t.Symbol]; ok {
+			continue
+		}
+		id := uint64(len(w.symbols))
+		w.symbols[t.Symbol] = id
+
+		body := binary.AppendUvarint(w.scratch[:0], id)
+		body = append(body, t.Symbol...)
+		if err := w.writeRecord(recSymbol, body); err != nil {
+			return err
+		}
+	}
+
+	body := binary.AppendUvarint(w.scratch[:0], uint64(at.UnixMilli()))
+	body = binary.AppendUvarint(body, uint64(len(tickers)))
+	for _, t := range tickers {
+		body = binary.AppendUvarint(body, w.symbols[t.Symbol])
+		body = binary.LittleEndian.AppendUint64(body, math.Float64bits(t.LastPrice))
+		body = binary.LittleEndian.AppendUint64(body, math.Float64bits(t.FairPrice))
+		body = binary.LittleEndian.AppendUint64(body, math.Float64bits(t.Volume24))
+	}
+	w.scratch = body
+	if err := w.writeRecord(recFrame, body); err != nil {
+		return err
+	}
+
+	if at.Sub(w.lastFlush) >= flushEvery {
+		w.lastFlush = at
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.closeFile()
+}
+
+func (w *Writer) writeRecord(kind byte, body []byte) error {
+	if len(body)+1 > MaxRecordSize {
+		return fmt.Errorf("recorder: record of %d bytes exceeds the %d byte limit", len(body)+1, MaxRecordSize)
+	}
+
+	var head [binary.MaxVarintLen64 + 1]byte
+	n := binary.PutUvarint(head[:], uint64(len(body)+1))
+	head[n] = kind
+
+	if _, err := w.buf.Write(head[:n+1]); err != nil {
+		return fmt.Errorf("recorder: write error: %w", err)
+	}
+	if _, err := w.buf.Write(body); err != nil {
+		return fmt.Errorf("recorder: write error: %w", err)
+	}
+	return nil
+}
+
+// countingWriter считает байты, дошедшие до файла.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (w *Writer) rotate(at time.Time) error {
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+
+	name := filepath.Join(w.opts.Dir, "ticks-"+at.UTC().Format("20060102-150405.000")+fileExt)
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("recorder: failed to create %s: %w", name, err)
+	}
+
+	w.file = f
+	w.counter = &countingWriter{w: f}
+	w.gz = gzip.NewWriter(w.counter)
+	w.buf = bufio.NewWriterSize(w.gz, 64<<10)
+	w.symbols = make(map[string]uint64)
+	w.opened = at
+	w.lastFlush = at
+
+	if _, err := w.buf.WriteString(magic); err != nil {
+		return err
+	}
+	return w.buf.WriteByte(version)
+}
+
+func (w *Writer) flush() error {
+	if w.buf == nil {
+		return nil
+	}
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	return w.gz.Flush()
+}
+
+func (w *Writer) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.buf.Flush()
+	if cerr := w.gz.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	w.file, w.counter, w.gz, w.buf = nil, nil, nil, nil
+	return err
+}